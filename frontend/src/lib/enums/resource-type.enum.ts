@@ -1,4 +1,7 @@
 export enum ResourceType {
   SERVICE = 'service',
   DIRECTORY = 'directory',
+  PROCESS = 'process',
+  ENDPOINT = 'endpoint',
+  VOLUME = 'volume',
 }
\ No newline at end of file
@@ -1,4 +1,10 @@
 export enum ServiceStatus {
+	UNKNOWN = 0,
 	STOPPED = 1,
-	RUNNING = 4
+	START_PENDING = 2,
+	STOP_PENDING = 3,
+	RUNNING = 4,
+	CONTINUE_PENDING = 5,
+	PAUSE_PENDING = 6,
+	PAUSED = 7
 }
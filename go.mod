@@ -3,6 +3,7 @@ module zenlight-support
 go 1.25.7
 
 require (
+	github.com/fsnotify/fsnotify v1.9.0
 	github.com/google/uuid v1.6.0
 	github.com/hashicorp/go-version v1.8.0
 	github.com/minio/selfupdate v0.6.0
@@ -0,0 +1,241 @@
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+	"zenlight-support/internal/bus"
+	"zenlight-support/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const resolvedAlertLimit = 200
+
+type alertKey struct {
+	resourceID string
+	rule       string
+}
+
+type compiledRule struct {
+	rule domain.AlertRule
+	cond domain.Condition
+}
+
+// AlertEngine evaluates threshold rules on watcher samples. An alert starts
+// pending, fires once its condition held for the rule's duration, can be
+// acknowledged, and resolves when the condition clears. There is at most one
+// open alert per resource and rule.
+type AlertEngine struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	rules    map[string][]compiledRule
+	open     map[alertKey]*domain.Alert
+	resolved []domain.Alert
+	silenced map[alertKey]time.Time
+	bus      *bus.Bus
+}
+
+func NewAlertEngine(cfg domain.Config, b *bus.Bus) *AlertEngine {
+	e := &AlertEngine{
+		now:      time.Now,
+		rules:    make(map[string][]compiledRule),
+		open:     make(map[alertKey]*domain.Alert),
+		silenced: make(map[alertKey]time.Time),
+		bus:      b,
+	}
+	e.rules = compileRules(cfg)
+	return e
+}
+
+func compileRules(cfg domain.Config) map[string][]compiledRule {
+	rules := make(map[string][]compiledRule)
+	for _, res := range cfg.Resources {
+		// Saved configs are validated, this only catches hand-edited files
+		if err := domain.ValidateAlertRules(res.Alerts); err != nil {
+			slog.Warn("Skipping invalid alert rules", slog.String("resource", res.Name), slog.String("error", err.Error()))
+			continue
+		}
+		for _, rule := range res.Alerts {
+			cond, _ := rule.Condition()
+			rules[res.ID] = append(rules[res.ID], compiledRule{rule: rule, cond: cond})
+		}
+	}
+	return rules
+}
+
+// Update replaces the rules. Open alerts whose rule was removed or changed
+// are dropped, they are re-raised on the next sample if still relevant.
+func (e *AlertEngine) Update(cfg domain.Config) {
+	rules := compileRules(cfg)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rules = rules
+	for key, a := range e.open {
+		keep := slices.ContainsFunc(rules[key.resourceID], func(cr compiledRule) bool {
+			return cr.rule.Name == key.rule && cr.rule.Expr == a.Expr
+		})
+		if !keep {
+			delete(e.open, key)
+			delete(e.silenced, key)
+		}
+	}
+}
+
+// Publish announces the alerts returned by Evaluate. Callers must not hold
+// any lock, a slow subscriber blocks the publisher.
+func (e *AlertEngine) Publish(alerts []domain.Alert) {
+	for _, alert := range alerts {
+		bus.Publish(e.bus, TopicAlert, alert)
+	}
+}
+
+// Evaluate updates the open alerts of cfg with a fresh sample and returns the
+// transitions to announce with Publish.
+func (e *AlertEngine) Evaluate(cfg domain.ResourceConfig, status domain.ResourceStatus) []domain.Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var out []domain.Alert
+	now := e.now()
+	for _, cr := range e.rules[cfg.ID] {
+		if !cr.cond.Measured(status) {
+			// No sample says whether the condition cleared, keep the alert as it is
+			continue
+		}
+		key := alertKey{resourceID: cfg.ID, rule: cr.rule.Name}
+		value, match := cr.cond.Eval(status)
+		alert, isOpen := e.open[key]
+
+		if !match {
+			if !isOpen {
+				continue
+			}
+			delete(e.open, key)
+			if alert.State == domain.AlertPending {
+				// Never fired, nobody was told about it
+				continue
+			}
+			alert.State = domain.AlertResolved
+			alert.Value = value
+			alert.ResolvedAt = now.UnixMilli()
+			e.resolve(*alert)
+			out = e.emit(out, key, *alert, now)
+			continue
+		}
+
+		if !isOpen {
+			alert = &domain.Alert{
+				ID:         uuid.NewString(),
+				ResourceID: cfg.ID,
+				Resource:   cfg.Name,
+				Rule:       cr.rule.Name,
+				Expr:       cr.rule.Expr,
+				Severity:   cr.rule.Level(),
+				State:      domain.AlertPending,
+				StartedAt:  now.UnixMilli(),
+			}
+			e.open[key] = alert
+		}
+		alert.Value = value
+
+		if alert.State == domain.AlertPending && now.Sub(time.UnixMilli(alert.StartedAt)) >= cr.cond.For {
+			alert.State = domain.AlertFiring
+			alert.FiredAt = now.UnixMilli()
+			slog.Warn("Alert firing", slog.String("resource", alert.Resource), slog.String("rule", alert.Rule), slog.Float64("value", value))
+			out = e.emit(out, key, *alert, now)
+		}
+	}
+	return out
+}
+
+// List returns open alerts, newest first, followed by resolved ones when asked.
+func (e *AlertEngine) List(includeResolved bool) []domain.Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	alerts := make([]domain.Alert, 0, len(e.open))
+	for key, a := range e.open {
+		alert := *a
+		alert.SilencedUntil = e.silencedUntil(key, now)
+		alerts = append(alerts, alert)
+	}
+	slices.SortFunc(alerts, func(a, b domain.Alert) int { return cmp.Compare(b.StartedAt, a.StartedAt) })
+
+	if includeResolved {
+		for i := len(e.resolved) - 1; i >= 0; i-- {
+			alerts = append(alerts, e.resolved[i])
+		}
+	}
+	return alerts
+}
+
+func (e *AlertEngine) Acknowledge(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, a := range e.open {
+		if a.ID != id {
+			continue
+		}
+		if a.State != domain.AlertFiring {
+			return fmt.Errorf("alert is %s, only firing alerts can be acknowledged", a.State)
+		}
+		a.State = domain.AlertAcknowledged
+		a.AckedAt = e.now().UnixMilli()
+		return nil
+	}
+	return fmt.Errorf("open alert not found for ID: %s", id)
+}
+
+// Silence mutes notifications for the alert's resource and rule for d,
+// including alerts that fire again later. A zero duration lifts the silence.
+func (e *AlertEngine) Silence(id string, d time.Duration) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for key, a := range e.open {
+		if a.ID != id {
+			continue
+		}
+		if d <= 0 {
+			delete(e.silenced, key)
+		} else {
+			e.silenced[key] = e.now().Add(d)
+		}
+		return nil
+	}
+	return fmt.Errorf("open alert not found for ID: %s", id)
+}
+
+func (e *AlertEngine) silencedUntil(key alertKey, now time.Time) int64 {
+	until, ok := e.silenced[key]
+	if !ok {
+		return 0
+	}
+	if !now.Before(until) {
+		delete(e.silenced, key)
+		return 0
+	}
+	return until.UnixMilli()
+}
+
+func (e *AlertEngine) resolve(alert domain.Alert) {
+	e.resolved = append(e.resolved, alert)
+	if len(e.resolved) > resolvedAlertLimit {
+		e.resolved = e.resolved[len(e.resolved)-resolvedAlertLimit:]
+	}
+}
+
+// emit adds alert to out, marked with when its silence ends so only the
+// notification is held back.
+func (e *AlertEngine) emit(out []domain.Alert, key alertKey, alert domain.Alert, now time.Time) []domain.Alert {
+	alert.SilencedUntil = e.silencedUntil(key, now)
+	return append(out, alert)
+}
@@ -2,20 +2,33 @@ package app
 
 import (
 	"context"
+	"path/filepath"
+	"time"
+	"zenlight-support/internal/bus"
 	"zenlight-support/internal/domain"
 	"zenlight-support/internal/repository"
 
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+const (
+	metricsDir = "metrics"
+	outboxDir  = "outbox"
+)
+
 type App struct {
-	Ctx     context.Context
-	cfg     domain.Config
-	mgr     domain.ResourceManager
-	repo    *repository.YamlConfigRepository
-	itemMap map[string]domain.ResourceConfig
-	watcher *ServiceWatcher
-	appVer  string
+	Ctx      context.Context
+	cfg      domain.Config
+	mgr      domain.ResourceManager
+	repo     *repository.YamlConfigRepository
+	itemMap  map[string]domain.ResourceConfig
+	watcher  *ServiceWatcher
+	logs     *logStreams
+	alerter  *LogAlerter
+	host     *HostMonitor
+	notifier *Notifier
+	bus      *bus.Bus
+	appVer   string
 }
 
 func NewApp(cfg domain.Config, mgr domain.ResourceManager, repo *repository.YamlConfigRepository, appVer string) *App {
@@ -24,12 +37,17 @@ func NewApp(cfg domain.Config, mgr domain.ResourceManager, repo *repository.Yaml
 		itemMap[item.ID] = item
 	}
 
+	b := bus.New()
 	return &App{
 		cfg:     cfg,
 		mgr:     mgr,
 		repo:    repo,
 		itemMap: itemMap,
-		watcher: NewServiceWatcher(cfg, mgr),
+		watcher: NewServiceWatcher(cfg, mgr, b),
+		logs:    newLogStreams(),
+		alerter: NewLogAlerter(cfg, b),
+		host:    NewHostMonitor(mgr, b),
+		bus:     b,
 		appVer:  appVer,
 	}
 }
@@ -42,20 +60,73 @@ func (a *App) Startup(ctx context.Context) {
 		return
 	}
 
+	a.applyMetrics()
+	go a.watcher.history.maintain(ctx)
+
+	incidents := repository.OpenIncidentStore(filepath.Join(filepath.Dir(a.repo.Path), incidentsFile))
+	if err := a.watcher.uptime.attach(incidents); err != nil {
+		wailsRuntime.LogError(a.Ctx, "Incident Log Open Error: "+err.Error())
+	}
+	a.watcher.uptime.configure(incidentRetention(a.cfg.Metrics))
+	a.watcher.uptime.start(time.Now())
+	go a.watcher.uptime.maintain(ctx)
+
+	// Started without channels too, they can be added by a later import
+	outbox, err := repository.OpenOutbox(filepath.Join(filepath.Dir(a.repo.Path), outboxDir))
+	if err != nil {
+		wailsRuntime.LogError(a.Ctx, "Notification Outbox Open Error: "+err.Error())
+	} else {
+		a.notifier = NewNotifier(a.cfg, outbox)
+		go a.notifier.Start(ctx)
+	}
+
+	a.subscribe(ctx)
+
 	go a.watcher.Start(ctx)
+	go a.alerter.Start(ctx)
+	go a.host.Start(ctx)
+}
 
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case updates := <-a.watcher.Updates():
-				wailsRuntime.EventsEmit(a.Ctx, "services-update", updates)
-			}
+// applyConfig hands a.cfg to the watcher, log alerter, notifier, metrics
+// store and incident retention and announces what changed.
+func (a *App) applyConfig(reason string) {
+	change := a.watcher.Update(a.cfg)
+	a.alerter.Update(a.cfg)
+	a.applyMetrics()
+	a.watcher.uptime.configure(incidentRetention(a.cfg.Metrics))
+	if a.notifier != nil {
+		a.notifier.Update(a.cfg)
+	}
+	change.Reason = reason
+	change.At = time.Now().UnixMilli()
+	bus.Publish(a.bus, TopicConfig, change)
+}
+
+// applyMetrics opens, reconfigures or closes the metrics store to match a.cfg.
+func (a *App) applyMetrics() {
+	history := a.watcher.history
+	metrics := a.cfg.Metrics
+	store := history.attached()
+
+	switch {
+	case !metrics.Enabled():
+		history.attach(nil)
+	case store != nil:
+		store.Configure(metrics.Retention(), metrics.CompactAfter())
+	default:
+		dir := filepath.Join(filepath.Dir(a.repo.Path), metricsDir)
+		store, err := repository.OpenMetricsStore(dir, metrics.Retention(), metrics.CompactAfter())
+		if err != nil {
+			wailsRuntime.LogError(a.Ctx, "Metrics Store Open Error: "+err.Error())
+			return
 		}
-	}()
+		history.attach(store)
+	}
 }
 
 func (a *App) Shutdown(ctx context.Context) {
+	a.logs.stopAll()
+	a.watcher.uptime.stop(time.Now())
+	a.watcher.history.close()
 	a.mgr.Disconnect()
 }
@@ -0,0 +1,203 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"zenlight-support/internal/domain"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// dirNotifier turns file system notifications under directory resources into
+// early polls. Notifications are best effort, the watcher still rescans every
+// directory on its regular interval.
+type dirNotifier struct {
+	mu      sync.Mutex
+	fs      *fsnotify.Watcher
+	roots   map[string]string // cleaned root path -> resource ID
+	watched map[string]bool   // roots currently registered with fs
+}
+
+func newDirNotifier(cfg domain.Config) *dirNotifier {
+	n := &dirNotifier{
+		roots:   make(map[string]string),
+		watched: make(map[string]bool),
+	}
+	for _, r := range cfg.Resources {
+		if r.Type == domain.DirectoryType {
+			n.roots[filepath.Clean(os.ExpandEnv(r.Path))] = r.ID
+		}
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Warn("Directory notifications unavailable, falling back to rescans", slog.String("error", err.Error()))
+	} else {
+		n.fs = w
+	}
+	return n
+}
+
+// update switches to the directories of cfg. Watches under removed roots are
+// dropped, new roots are registered by ensure on their first rescan.
+func (n *dirNotifier) update(cfg domain.Config) {
+	roots := make(map[string]string)
+	for _, r := range cfg.Resources {
+		if r.Type == domain.DirectoryType {
+			roots[filepath.Clean(os.ExpandEnv(r.Path))] = r.ID
+		}
+	}
+
+	n.mu.Lock()
+	old := n.roots
+	n.roots = roots
+	var removed []string
+	for root := range old {
+		if _, ok := roots[root]; !ok {
+			delete(n.watched, root)
+			removed = append(removed, root)
+		}
+	}
+	n.mu.Unlock()
+
+	if n.fs == nil {
+		return
+	}
+	for _, path := range n.fs.WatchList() {
+		for _, root := range removed {
+			if _, _, owned := n.owner(path); !owned && within(path, root) {
+				_ = n.fs.Remove(path)
+			}
+		}
+	}
+}
+
+// run delivers the ID of every resource whose tree changed until ctx is done.
+func (n *dirNotifier) run(ctx context.Context, changed func(id string)) {
+	if n.fs == nil {
+		return
+	}
+	defer n.fs.Close()
+
+	n.mu.Lock()
+	roots := make([]string, 0, len(n.roots))
+	for root := range n.roots {
+		roots = append(roots, root)
+	}
+	n.mu.Unlock()
+
+	for _, root := range roots {
+		n.ensure(root)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-n.fs.Events:
+			if !ok {
+				return
+			}
+			n.handle(ev, changed)
+		case err, ok := <-n.fs.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("Directory notification error", slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (n *dirNotifier) handle(ev fsnotify.Event, changed func(id string)) {
+	path := filepath.Clean(ev.Name)
+	root, id, ok := n.owner(path)
+	if !ok {
+		return
+	}
+
+	switch {
+	case ev.Has(fsnotify.Create):
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			n.addTree(path)
+		}
+	case ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename):
+		if path == root {
+			// The watch is gone with the directory, ensure re-adds it once it is back
+			n.mu.Lock()
+			delete(n.watched, root)
+			n.mu.Unlock()
+		}
+	}
+
+	changed(id)
+}
+
+// ensure registers root and its subdirectories unless already done. The
+// watcher calls it after each rescan so directories created later are picked up.
+func (n *dirNotifier) ensure(path string) {
+	if n.fs == nil {
+		return
+	}
+	root := filepath.Clean(os.ExpandEnv(path))
+
+	n.mu.Lock()
+	if _, ok := n.roots[root]; !ok || n.watched[root] {
+		// Already watched, or no longer a configured directory
+		n.mu.Unlock()
+		return
+	}
+	n.mu.Unlock()
+
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return
+	}
+	if err := n.addTree(root); err != nil {
+		slog.Warn("Failed to watch directory", slog.String("path", root), slog.String("error", err.Error()))
+		return
+	}
+
+	n.mu.Lock()
+	n.watched[root] = true
+	n.mu.Unlock()
+}
+
+// addTree watches dir and every directory below it, fsnotify is not recursive.
+func (n *dirNotifier) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := n.fs.Add(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+}
+
+func (n *dirNotifier) owner(path string) (root, id string, ok bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for root, id := range n.roots {
+		if within(path, root) {
+			return root, id, true
+		}
+	}
+	return "", "", false
+}
+
+func within(path, root string) bool {
+	return path == root || strings.HasPrefix(path, root+string(filepath.Separator))
+}
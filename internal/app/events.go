@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"zenlight-support/internal/bus"
+	"zenlight-support/internal/domain"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Topics published on the app's event bus.
+var (
+	TopicStatus   = bus.NewTopic[[]domain.ResourceStatus]("status")
+	TopicHost     = bus.NewTopic[domain.HostMetrics]("metrics")
+	TopicAlert    = bus.NewTopic[domain.Alert]("alerts")
+	TopicLogAlert = bus.NewTopic[domain.LogAlert]("log-alerts")
+	TopicRestart  = bus.NewTopic[domain.RestartAttempt]("restarts")
+	TopicInstall  = bus.NewTopic[domain.InstallEvent]("installs")
+	TopicConfig   = bus.NewTopic[domain.ConfigChange]("config")
+)
+
+// uiEvents maps topics to the event names the frontend listens on.
+var uiEvents = struct {
+	status, host, alert, logAlert, restart, install, config string
+}{
+	status:   "services-update",
+	host:     "host-update",
+	alert:    "alert",
+	logAlert: "log-alert",
+	restart:  "service-restart",
+	install:  "install-update",
+	config:   "config-update",
+}
+
+// The UI and the notifier must not miss state changes, they are fast enough to block on
+var reliable = bus.Options{Buffer: 64, Policy: bus.Block}
+
+// forward runs fn for every event on t until ctx is done.
+func forward[T any](ctx context.Context, b *bus.Bus, t bus.Topic[T], opts bus.Options, fn func(T)) {
+	sub := bus.Subscribe(b, t, opts)
+	go func() {
+		<-ctx.Done()
+		sub.Close()
+	}()
+	go func() {
+		for v := range sub.C() {
+			fn(v)
+		}
+	}()
+}
+
+// emitTo returns a handler that forwards events to the frontend under name.
+func emitTo[T any](a *App, name string) func(T) {
+	return func(v T) {
+		wailsRuntime.EventsEmit(a.Ctx, name, v)
+	}
+}
+
+// subscribe wires the built-in consumers. It runs before any publisher starts.
+func (a *App) subscribe(ctx context.Context) {
+	forward(ctx, a.bus, TopicStatus, reliable, emitTo[[]domain.ResourceStatus](a, uiEvents.status))
+	forward(ctx, a.bus, TopicHost, bus.Options{Policy: bus.DropOldest}, emitTo[domain.HostMetrics](a, uiEvents.host))
+	forward(ctx, a.bus, TopicRestart, reliable, emitTo[domain.RestartAttempt](a, uiEvents.restart))
+	forward(ctx, a.bus, TopicInstall, reliable, emitTo[domain.InstallEvent](a, uiEvents.install))
+	forward(ctx, a.bus, TopicConfig, reliable, emitTo[domain.ConfigChange](a, uiEvents.config))
+
+	forward(ctx, a.bus, TopicAlert, reliable, func(alert domain.Alert) {
+		wailsRuntime.EventsEmit(a.Ctx, uiEvents.alert, alert)
+		if alert.SilencedUntil == 0 {
+			a.notify(alertNotification(alert))
+		}
+	})
+	forward(ctx, a.bus, TopicLogAlert, reliable, func(alert domain.LogAlert) {
+		wailsRuntime.EventsEmit(a.Ctx, uiEvents.logAlert, alert)
+		a.notify(logAlertNotification(alert))
+	})
+
+	forward(ctx, a.bus, TopicInstall, reliable, func(ev domain.InstallEvent) {
+		slog.Info("Install", slog.String("name", ev.Name), slog.String("stage", string(ev.Stage)), slog.String("error", ev.Error))
+	})
+	forward(ctx, a.bus, TopicConfig, reliable, func(ch domain.ConfigChange) {
+		slog.Info("Config changed", slog.String("reason", ch.Reason), slog.Int("added", len(ch.Added)), slog.Int("removed", len(ch.Removed)), slog.Int("updated", len(ch.Updated)))
+	})
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"fmt"
+	"time"
+	"zenlight-support/internal/domain"
+)
+
+// GetAlerts lists open alerts and, when asked, recently resolved ones.
+func (a *App) GetAlerts(includeResolved bool) []domain.Alert {
+	return a.watcher.alerts.List(includeResolved)
+}
+
+func (a *App) AcknowledgeAlert(id string) error {
+	return a.watcher.alerts.Acknowledge(id)
+}
+
+// SilenceAlert mutes the alert's rule for the given minutes, zero unmutes it.
+func (a *App) SilenceAlert(id string, minutes int) error {
+	return a.watcher.alerts.Silence(id, time.Duration(minutes)*time.Minute)
+}
+
+// TestNotification sends a sample message through the named channel.
+func (a *App) TestNotification(channel string) error {
+	if a.notifier == nil {
+		return fmt.Errorf("notification outbox is not available")
+	}
+	return a.notifier.Test(a.Ctx, channel)
+}
+
+func (a *App) notify(note domain.Notification) {
+	if a.notifier != nil {
+		a.notifier.Notify(note)
+	}
+}
@@ -73,6 +73,14 @@ func (a *App) ImportConfig() error {
 	if err := yaml.Unmarshal(content, &cfg); err != nil {
 		return fmt.Errorf("invalid config format: %w", err)
 	}
+	if err := cfg.ValidateDependencies(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	for _, r := range cfg.Resources {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("invalid config: %s: %w", r.Name, err)
+		}
+	}
 
 	backupPath := fmt.Sprintf("%s.%s.bak", a.repo.Path, time.Now().Format(time.DateTime))
 	currentData, err := os.ReadFile(a.repo.Path)
@@ -89,6 +97,7 @@ func (a *App) ImportConfig() error {
 	for _, item := range cfg.Resources {
 		a.itemMap[item.ID] = item
 	}
+	a.applyConfig("import")
 
 	return nil
 }
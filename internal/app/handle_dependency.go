@@ -0,0 +1,121 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"time"
+	"zenlight-support/internal/domain"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	actionStart = "start"
+	actionStop  = "stop"
+)
+
+// RestartService stops the service and everything depending on it, then
+// brings them back up in dependency order.
+func (a *App) RestartService(id string) ([]domain.StepResult, error) {
+	cfg, ok := a.itemMap[id]
+	if !ok {
+		return nil, fmt.Errorf("service config not found for ID: %s", id)
+	}
+	if cfg.Type != domain.ServiceType {
+		return nil, fmt.Errorf("resource is not a service: %s", id)
+	}
+
+	stopOrder, err := a.cfg.StopOrder([]string{id})
+	if err != nil {
+		return nil, err
+	}
+
+	results := a.runSteps(stopOrder, actionStop)
+	stopped := make(map[string]bool, len(results))
+	for _, r := range results {
+		stopped[r.ID] = r.Success
+	}
+
+	// Whatever went down comes back up, even when part of the group failed to stop
+	startOrder := slices.Clone(stopOrder)
+	slices.Reverse(startOrder)
+	startOrder = slices.DeleteFunc(startOrder, func(r domain.ResourceConfig) bool { return !stopped[r.ID] })
+	results = append(results, a.runSteps(startOrder, actionStart)...)
+
+	if failed := slices.IndexFunc(results, func(r domain.StepResult) bool { return !r.Success }); failed >= 0 {
+		return results, fmt.Errorf("failed to %s %s: %s", results[failed].Action, results[failed].Name, results[failed].Error)
+	}
+	return results, nil
+}
+
+// StartGroup starts the given resources and their dependencies, dependencies first.
+func (a *App) StartGroup(ids []string) ([]domain.StepResult, error) {
+	order, err := a.cfg.StartOrder(ids)
+	if err != nil {
+		return nil, err
+	}
+	return a.runSteps(order, actionStart), nil
+}
+
+// StopGroup stops the given resources and everything depending on them, dependents first.
+func (a *App) StopGroup(ids []string) ([]domain.StepResult, error) {
+	order, err := a.cfg.StopOrder(ids)
+	if err != nil {
+		return nil, err
+	}
+	return a.runSteps(order, actionStop), nil
+}
+
+// runSteps applies action to each service in order. A step is skipped when a
+// resource it waits on (a dependency for start, a dependent for stop) failed.
+func (a *App) runSteps(order []domain.ResourceConfig, action string) []domain.StepResult {
+	failed := make(map[string]bool)
+	results := make([]domain.StepResult, 0, len(order))
+
+	blockedBy := func(r domain.ResourceConfig) bool {
+		if action == actionStart {
+			return slices.ContainsFunc(r.DependsOn, func(dep string) bool { return failed[dep] })
+		}
+		for _, other := range a.cfg.Resources {
+			if failed[other.ID] && slices.Contains(other.DependsOn, r.ID) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, r := range order {
+		if r.Type != domain.ServiceType {
+			continue
+		}
+
+		step := domain.StepResult{ID: r.ID, Name: r.Name, Action: action}
+		if blockedBy(r) {
+			failed[r.ID] = true
+			step.Skipped = true
+			step.Error = "skipped because a related service failed"
+			results = append(results, step)
+			continue
+		}
+
+		start := time.Now()
+		var err error
+		if action == actionStart {
+			err = a.startAndWait(r)
+		} else {
+			err = a.stopAndWait(r)
+		}
+		step.Duration = time.Since(start).Milliseconds()
+
+		if err != nil {
+			failed[r.ID] = true
+			step.Error = err.Error()
+			wailsRuntime.LogError(a.Ctx, fmt.Sprintf("Failed to %s %s: %s", action, r.Name, err))
+		} else {
+			step.Success = true
+		}
+		results = append(results, step)
+	}
+
+	return results
+}
@@ -0,0 +1,26 @@
+package app
+
+import (
+	"fmt"
+	"time"
+	"zenlight-support/internal/domain"
+)
+
+// GetMetricHistory returns the samples of a resource between from and to
+// (Unix ms) downsampled into steps of step ms. Zero values chart the last hour.
+func (a *App) GetMetricHistory(id string, from, to, step int64) (*domain.MetricHistory, error) {
+	if _, ok := a.itemMap[id]; !ok {
+		return nil, fmt.Errorf("resource config not found for ID: %s", id)
+	}
+
+	from, to, step = historyRange(from, to, step, time.Now())
+	samples := a.watcher.history.between(id, from, to)
+
+	return &domain.MetricHistory{
+		ID:     id,
+		From:   from,
+		To:     to,
+		Step:   step,
+		Points: domain.Downsample(samples, from, to, step),
+	}, nil
+}
@@ -0,0 +1,9 @@
+package app
+
+import "zenlight-support/internal/domain"
+
+// GetHostMetrics returns CPU, memory, load, disk and network figures for the
+// machine as of the host monitor's latest sample.
+func (a *App) GetHostMetrics() (*domain.HostMetrics, error) {
+	return a.host.Latest()
+}
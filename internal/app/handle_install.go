@@ -1,71 +1,194 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
+	"zenlight-support/internal/bus"
 	"zenlight-support/internal/domain"
+	"zenlight-support/pkg/file"
+	"zenlight-support/pkg/health"
 
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-func (a *App) Install(id string, files []domain.InstallFileDTO) error {
+// installHealthTimeout is how long a freshly installed resource gets to pass
+// its health check before the install is rolled back.
+const installHealthTimeout = 30 * time.Second
+
+func (a *App) Install(id string, files []domain.InstallFileDTO) (err error) {
 	cfg, ok := a.itemMap[id]
 	if !ok {
 		return fmt.Errorf("service config not found for ID: %s", id)
 	}
 
+	a.publishInstall(cfg, domain.InstallStarted, len(files), nil)
+	defer func() {
+		if err != nil {
+			a.publishInstall(cfg, domain.InstallFailed, len(files), err)
+		} else {
+			a.publishInstall(cfg, domain.InstallCompleted, len(files), nil)
+		}
+	}()
+
 	serviceName := cfg.ServiceName
 	targetPath := filepath.Clean(os.ExpandEnv(cfg.Path))
 
 	wailsRuntime.LogInfo(a.Ctx, "Installing service files to: "+targetPath)
 
+	// Stage everything first so a failed write leaves the live files alone
+	stage, err := file.Stage(targetPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cleanupErr := stage.Cleanup(); cleanupErr != nil {
+			wailsRuntime.LogWarning(a.Ctx, "Failed to clean up install staging: "+cleanupErr.Error())
+		}
+	}()
+
+	for _, f := range files {
+		if file.IsArchive(f.Name) {
+			n, err := stage.Extract(f.Name, f.Data, f.StripPrefix)
+			if err != nil {
+				return fmt.Errorf("failed to extract package %s: %w", f.Name, err)
+			}
+			wailsRuntime.LogInfo(a.Ctx, fmt.Sprintf("Staged %d files from package: %s", n, f.Name))
+			continue
+		}
+
+		wailsRuntime.LogInfo(a.Ctx, "Staging file: "+f.Name)
+		if err := stage.Write(f.Name, f.Data, 0755); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", f.Name, err)
+		}
+	}
+
 	// Stop service if running
 	if cfg.Type == domain.ServiceType {
-		if err := a.stopAndWait(serviceName); err != nil {
+		if err := a.stopAndWait(cfg); err != nil {
 			return fmt.Errorf("failed to stop service: %w", err)
 		}
 	}
 
-	if err := os.MkdirAll(targetPath, 0755); err != nil {
-		return fmt.Errorf("failed to create service directory: %w", err)
-	}
-
-	for _, file := range files {
-		filePath := filepath.Join(targetPath, file.Name)
-		wailsRuntime.LogInfo(a.Ctx, "Writing file: "+filePath)
-		if err := os.WriteFile(filePath, file.Data, 0755); err != nil {
-			return fmt.Errorf("failed to write file %s: %w", file.Name, err)
+	if err := stage.Commit(); err != nil {
+		if errors.Is(err, file.ErrRollback) {
+			// Old and new files are mixed, starting the service on them would only make it worse
+			return fmt.Errorf("failed to swap in service files, previous files not restored and service left stopped: %w", err)
 		}
+		// Commit already put the previous files back
+		return a.restartAfterRollback(cfg, fmt.Errorf("failed to swap in service files: %w", err))
 	}
 
 	wailsRuntime.LogInfo(a.Ctx, "Service files installed for: "+serviceName)
+	a.watcher.Burst(id)
 
 	// Start service after installation
 	if cfg.Type == domain.ServiceType {
-		if err := a.startAndWait(serviceName); err != nil {
-			return fmt.Errorf("failed to start service: %w", err)
+		if err := a.startAndWait(cfg); err != nil {
+			return a.rollbackInstall(cfg, stage, fmt.Errorf("failed to start service: %w", err))
 		}
 	}
 
+	if err := a.waitHealthy(cfg); err != nil {
+		return a.rollbackInstall(cfg, stage, err)
+	}
+
 	return nil
 }
 
-func (a *App) startAndWait(serviceName string) error {
-	state, err := a.mgr.GetResourceState(serviceName)
-	if err != nil {
-		return err
+// rollbackInstall puts the previous files back after the new ones failed to
+// come up and starts the service on them again. It returns cause, annotated
+// with how the rollback went.
+func (a *App) rollbackInstall(cfg domain.ResourceConfig, stage *file.Staged, cause error) error {
+	wailsRuntime.LogWarning(a.Ctx, "Install failed, restoring previous files: "+cause.Error())
+
+	// Restore the files even when the stop fails, a locked file then fails
+	// the rollback on its own and everything else still goes back
+	if cfg.Type == domain.ServiceType {
+		if err := a.stopAndWait(cfg); err != nil {
+			cause = fmt.Errorf("%w (rollback could not stop service: %v)", cause, err)
+		}
 	}
+	if err := stage.Rollback(); err != nil {
+		return fmt.Errorf("%w (rollback failed: %v)", cause, err)
+	}
+	return a.restartAfterRollback(cfg, cause)
+}
 
-	if state == domain.RUNNING {
+func (a *App) restartAfterRollback(cfg domain.ResourceConfig, cause error) error {
+	a.publishInstall(cfg, domain.InstallRolledBack, 0, cause)
+
+	if cfg.Type == domain.ServiceType {
+		if err := a.startAndWait(cfg); err != nil {
+			return fmt.Errorf("%w (previous files restored but service failed to start: %v)", cause, err)
+		}
+	}
+	return fmt.Errorf("%w (previous files restored)", cause)
+}
+
+// waitHealthy runs the resource's health check until it passes or
+// installHealthTimeout runs out. Resources without one pass right away.
+func (a *App) waitHealthy(cfg domain.ResourceConfig) error {
+	if cfg.HealthCheck == nil {
 		return nil
 	}
 
-	if err := a.mgr.StartService(serviceName); err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), installHealthTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		result := health.Run(ctx, *cfg.HealthCheck)
+		if result.Up {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("health check failed after install: %s", result.Error)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *App) publishInstall(cfg domain.ResourceConfig, stage domain.InstallStage, files int, err error) {
+	ev := domain.InstallEvent{ID: cfg.ID, Name: cfg.Name, Stage: stage, Files: files, At: time.Now().UnixMilli()}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	bus.Publish(a.bus, TopicInstall, ev)
+}
+
+func (a *App) startAndWait(cfg domain.ResourceConfig) error {
+	serviceName := cfg.ServiceName
+	a.watcher.supervisor.Resume(cfg.ID)
+	a.watcher.Burst(cfg.ID)
+
+	state, err := a.mgr.GetResourceState(serviceName)
+	if err != nil {
 		return err
 	}
 
+	switch state {
+	case domain.RUNNING:
+		return nil
+	case domain.START_PENDING, domain.CONTINUE_PENDING:
+		// Already on its way up, just wait below
+	case domain.PAUSED:
+		if err := a.mgr.ContinueService(serviceName); err != nil {
+			return err
+		}
+	default:
+		if err := a.mgr.StartService(serviceName); err != nil {
+			return err
+		}
+	}
+
 	timeout := time.After(30 * time.Second) // timeout after 30 seconds
 	ticker := time.NewTicker(500 * time.Millisecond)
 	defer ticker.Stop()
@@ -86,18 +209,30 @@ func (a *App) startAndWait(serviceName string) error {
 	}
 }
 
-func (a *App) stopAndWait(serviceName string) error {
+func (a *App) stopAndWait(cfg domain.ResourceConfig) (err error) {
+	serviceName := cfg.ServiceName
+	a.suspend(cfg.ID)
+	defer func() {
+		if err != nil {
+			a.unsuspend(cfg.ID)
+		}
+	}()
+	a.watcher.Burst(cfg.ID)
+
 	state, err := a.mgr.GetResourceState(serviceName)
 	if err != nil {
 		return err
 	}
 
-	if state == domain.STOPPED {
+	switch state {
+	case domain.STOPPED:
 		return nil
-	}
-
-	if err := a.mgr.StopService(serviceName); err != nil {
-		return err
+	case domain.STOP_PENDING:
+		// Already on its way down, just wait below
+	default:
+		if err := a.mgr.StopService(serviceName); err != nil {
+			return err
+		}
 	}
 
 	timeout := time.After(30 * time.Second) // timeout after 30 seconds
@@ -119,3 +254,17 @@ func (a *App) stopAndWait(serviceName string) error {
 		}
 	}
 }
+
+// suspend tells the supervisor and the uptime tracker that the operator is
+// about to stop id, so it is neither restarted nor counted as an outage.
+func (a *App) suspend(id string) {
+	a.watcher.supervisor.Suspend(id)
+	a.watcher.uptime.plan(id, "stopped from the app")
+}
+
+// unsuspend undoes suspend after a stop that did not happen. The service may
+// still be up, so it stays supervised and its next outage is unplanned.
+func (a *App) unsuspend(id string) {
+	a.watcher.supervisor.Resume(id)
+	a.watcher.uptime.unplan(id)
+}
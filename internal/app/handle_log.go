@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"zenlight-support/internal/domain"
+	"zenlight-support/pkg/tail"
+
+	"github.com/google/uuid"
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const maxBackfill = 5000
+
+// logStreams tracks the running log viewers, each with its own followers and
+// filters. A resource has at most one stream, a new one replaces the old.
+type logStreams struct {
+	mu      sync.Mutex
+	streams map[string]*logStream // per stream ID
+}
+
+type logStream struct {
+	resourceID string
+	cancel     context.CancelFunc
+}
+
+func newLogStreams() *logStreams {
+	return &logStreams{streams: make(map[string]*logStream)}
+}
+
+// StartLogStream follows every log file of a resource and emits "log-lines"
+// events until StopLogStream is called, another stream is started for the
+// same resource or the page is reloaded. It returns the stream ID.
+func (a *App) StartLogStream(id string, opts domain.LogStreamOptions) (string, error) {
+	cfg, ok := a.itemMap[id]
+	if !ok {
+		return "", fmt.Errorf("resource config not found for ID: %s", id)
+	}
+	if len(cfg.Logs) == 0 {
+		return "", fmt.Errorf("resource has no log files: %s", id)
+	}
+
+	// Validate the filter up front so the caller gets the error
+	if _, err := tail.NewFilter(opts.Level, opts.Pattern); err != nil {
+		return "", err
+	}
+
+	streamID, ctx := a.logs.start(a.Ctx, id)
+
+	var wg sync.WaitGroup
+	backfill := min(opts.Backfill, maxBackfill)
+	for _, pattern := range cfg.Logs {
+		// Filters keep per-file state for continuation lines
+		filter, _ := tail.NewFilter(opts.Level, opts.Pattern)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := tail.Follow(ctx, pattern, tail.Options{Backfill: backfill}, func(lines []tail.Line) {
+				if lines = filter.Apply(lines); len(lines) == 0 {
+					return
+				}
+				wailsRuntime.EventsEmit(a.Ctx, "log-lines", domain.LogBatch{StreamID: streamID, ResourceID: id, Lines: lines})
+			})
+			if err != nil {
+				wailsRuntime.LogError(a.Ctx, "Log stream error: "+err.Error())
+				wailsRuntime.EventsEmit(a.Ctx, "log-lines", domain.LogBatch{StreamID: streamID, ResourceID: id, Error: err.Error()})
+			}
+		}()
+	}
+
+	// A stream whose followers all failed is over
+	go func() {
+		wg.Wait()
+		a.logs.stop(streamID)
+	}()
+
+	return streamID, nil
+}
+
+func (a *App) StopLogStream(streamID string) error {
+	if !a.logs.stop(streamID) {
+		return fmt.Errorf("log stream not found: %s", streamID)
+	}
+	return nil
+}
+
+// DomReady runs on every page load. Streams started by a previous load have
+// no viewer left to stop them.
+func (a *App) DomReady(ctx context.Context) {
+	a.logs.stopAll()
+}
+
+// GetLogFiles returns the file currently followed for each log pattern of a resource.
+func (a *App) GetLogFiles(id string) ([]string, error) {
+	cfg, ok := a.itemMap[id]
+	if !ok {
+		return nil, fmt.Errorf("resource config not found for ID: %s", id)
+	}
+
+	var files []string
+	for _, pattern := range cfg.Logs {
+		if path, err := tail.Resolve(pattern); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// start registers a stream for resourceID, stopping the one it replaces. The
+// returned context ends when the stream is stopped.
+func (s *logStreams) start(parent context.Context, resourceID string) (string, context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, st := range s.streams {
+		if st.resourceID == resourceID {
+			st.cancel()
+			delete(s.streams, id)
+		}
+	}
+
+	streamID := uuid.NewString()
+	ctx, cancel := context.WithCancel(parent)
+	s.streams[streamID] = &logStream{resourceID: resourceID, cancel: cancel}
+	return streamID, ctx
+}
+
+// stop cancels a stream and reports whether it was running.
+func (s *logStreams) stop(streamID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.streams[streamID]
+	if !ok {
+		return false
+	}
+	st.cancel()
+	delete(s.streams, streamID)
+	return true
+}
+
+func (s *logStreams) stopAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, st := range s.streams {
+		st.cancel()
+		delete(s.streams, id)
+	}
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"fmt"
+	"zenlight-support/internal/domain"
+)
+
+func (a *App) KillProcess(id string) error {
+	cfg, err := a.processConfig(id)
+	if err != nil {
+		return err
+	}
+	a.watcher.uptime.plan(id, "killed from the app")
+	a.watcher.Burst(id)
+	if err := a.mgr.KillProcess(*cfg.Process); err != nil {
+		a.watcher.uptime.unplan(id)
+		return err
+	}
+	return nil
+}
+
+func (a *App) LaunchProcess(id string) error {
+	cfg, err := a.processConfig(id)
+	if err != nil {
+		return err
+	}
+	a.watcher.Burst(id)
+	return a.mgr.LaunchProcess(*cfg.Process, cfg.Path)
+}
+
+func (a *App) processConfig(id string) (domain.ResourceConfig, error) {
+	cfg, ok := a.itemMap[id]
+	if !ok {
+		return cfg, fmt.Errorf("process config not found for ID: %s", id)
+	}
+	if cfg.Type != domain.ProcessType || cfg.Process == nil {
+		return cfg, fmt.Errorf("resource is not a process: %s", id)
+	}
+	return cfg, nil
+}
@@ -1,8 +1,11 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"zenlight-support/internal/domain"
+	"zenlight-support/pkg/health"
 
 	"github.com/google/uuid"
 )
@@ -15,6 +18,37 @@ func (a *App) GetDirectories() []domain.ResourceConfig {
 	return a.filterByType(domain.DirectoryType)
 }
 
+func (a *App) GetProcesses() []domain.ResourceConfig {
+	return a.filterByType(domain.ProcessType)
+}
+
+func (a *App) GetEndpoints() []domain.ResourceConfig {
+	return a.filterByType(domain.EndpointType)
+}
+
+func (a *App) GetVolumeResources() []domain.ResourceConfig {
+	return a.filterByType(domain.VolumeType)
+}
+
+// GetVolumes lists every mounted volume on the host, configured or not.
+func (a *App) GetVolumes() ([]domain.VolumeInfo, error) {
+	return a.mgr.ListVolumes()
+}
+
+// CheckHealth runs the resource's health check immediately instead of waiting for the watcher.
+func (a *App) CheckHealth(id string) (*health.Result, error) {
+	cfg, ok := a.itemMap[id]
+	if !ok {
+		return nil, fmt.Errorf("resource config not found for ID: %s", id)
+	}
+	if cfg.HealthCheck == nil {
+		return nil, fmt.Errorf("resource has no health check: %s", id)
+	}
+
+	result := health.Run(context.Background(), *cfg.HealthCheck)
+	return &result, nil
+}
+
 func (a *App) GetResourceMetrics(id string) (*domain.ResourceMetrics, error) {
 	cfg, ok := a.itemMap[id]
 	if !ok {
@@ -29,6 +63,14 @@ func (a *App) GetResourceMetrics(id string) (*domain.ResourceMetrics, error) {
 		return a.mgr.GetDirectoryMetrics(cfg.Path)
 	}
 
+	if cfg.Type == domain.VolumeType {
+		return a.mgr.GetVolumeMetrics(cfg.Path)
+	}
+
+	if cfg.Type == domain.ProcessType && cfg.Process != nil {
+		return a.mgr.GetProcessMetrics(*cfg.Process)
+	}
+
 	return nil, fmt.Errorf("unsupported resource type for metrics: %s", id)
 }
 
@@ -43,14 +85,16 @@ func (a *App) filterByType(rType domain.ResourceType) []domain.ResourceConfig {
 }
 
 func (a *App) SaveResource(resource domain.ResourceConfig) (*domain.ResourceConfig, error) {
+	resources := slices.Clone(a.cfg.Resources)
+
 	if resource.ID == "" {
 		resource.ID = uuid.NewString()
-		a.cfg.Resources = append(a.cfg.Resources, resource)
+		resources = append(resources, resource)
 	} else {
 		found := false
-		for i, r := range a.cfg.Resources {
+		for i, r := range resources {
 			if r.ID == resource.ID {
-				a.cfg.Resources[i] = resource
+				resources[i] = resource
 				found = true
 				break
 			}
@@ -60,11 +104,23 @@ func (a *App) SaveResource(resource domain.ResourceConfig) (*domain.ResourceConf
 		}
 	}
 
+	if err := resource.Validate(); err != nil {
+		return nil, err
+	}
+
+	candidate := a.cfg
+	candidate.Resources = resources
+	if err := candidate.ValidateDependencies(); err != nil {
+		return nil, err
+	}
+
+	a.cfg.Resources = resources
 	a.itemMap[resource.ID] = resource
 
 	if err := a.repo.Save(&a.cfg); err != nil {
 		return nil, fmt.Errorf("failed to save config: %w", err)
 	}
+	a.applyConfig("save")
 
 	return &resource, nil
 }
@@ -74,18 +130,22 @@ func (a *App) DeleteResource(id string) error {
 		return fmt.Errorf("resource not found for ID: %s", id)
 	}
 
-	for i, r := range a.cfg.Resources {
-		if r.ID == id {
-			a.cfg.Resources = append(a.cfg.Resources[:i], a.cfg.Resources[i+1:]...)
-			break
+	for _, r := range a.cfg.Resources {
+		if slices.Contains(r.DependsOn, id) {
+			return fmt.Errorf("resource is required by %s", r.Name)
 		}
 	}
 
+	a.cfg.Resources = slices.DeleteFunc(slices.Clone(a.cfg.Resources), func(r domain.ResourceConfig) bool {
+		return r.ID == id
+	})
+
 	delete(a.itemMap, id)
 
 	if err := a.repo.Save(&a.cfg); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
+	a.applyConfig("delete")
 
 	return nil
 }
@@ -14,6 +14,8 @@ func (a *App) StartService(id string) error {
 	if !ok {
 		return fmt.Errorf("service config not found for ID: %s", id)
 	}
+	a.watcher.supervisor.Resume(id)
+	a.watcher.Burst(id)
 	return a.mgr.StartService(cfg.ServiceName)
 }
 
@@ -25,7 +27,37 @@ func (a *App) StopService(id string) error {
 	if cfg.Type != domain.ServiceType {
 		return fmt.Errorf("resource is not a service: %s", id)
 	}
-	return a.mgr.StopService(cfg.ServiceName)
+	a.suspend(id)
+	a.watcher.Burst(id)
+	if err := a.mgr.StopService(cfg.ServiceName); err != nil {
+		a.unsuspend(id)
+		return err
+	}
+	return nil
+}
+
+func (a *App) PauseService(id string) error {
+	cfg, ok := a.itemMap[id]
+	if !ok {
+		return fmt.Errorf("service config not found for ID: %s", id)
+	}
+	if cfg.Type != domain.ServiceType {
+		return fmt.Errorf("resource is not a service: %s", id)
+	}
+	a.watcher.Burst(id)
+	return a.mgr.PauseService(cfg.ServiceName)
+}
+
+func (a *App) ContinueService(id string) error {
+	cfg, ok := a.itemMap[id]
+	if !ok {
+		return fmt.Errorf("service config not found for ID: %s", id)
+	}
+	if cfg.Type != domain.ServiceType {
+		return fmt.Errorf("resource is not a service: %s", id)
+	}
+	a.watcher.Burst(id)
+	return a.mgr.ContinueService(cfg.ServiceName)
 }
 
 func (a *App) GetServiceStatus(id string) (domain.Status, error) {
@@ -40,6 +72,24 @@ func (a *App) GetServiceStatus(id string) (domain.Status, error) {
 	return a.mgr.GetResourceState(cfg.ServiceName)
 }
 
+// GetAllStatuses returns the watcher's latest view of every resource, for
+// frontends that connect after the last "services-update".
+func (a *App) GetAllStatuses() []domain.ResourceStatus {
+	return a.watcher.Snapshot(false)
+}
+
+// RefreshAllStatuses probes every watched resource now and returns the result.
+func (a *App) RefreshAllStatuses() []domain.ResourceStatus {
+	return a.watcher.Snapshot(true)
+}
+
+func (a *App) GetRestartHistory(id string) ([]domain.RestartAttempt, error) {
+	if _, ok := a.itemMap[id]; !ok {
+		return nil, fmt.Errorf("service config not found for ID: %s", id)
+	}
+	return a.watcher.supervisor.History(id), nil
+}
+
 func (a *App) OpenExplorer(id string) error {
 	cfg, ok := a.itemMap[id]
 	if !ok {
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"fmt"
+	"time"
+	"zenlight-support/internal/domain"
+)
+
+// GetIncidents returns the downtime incidents of a resource overlapping from
+// and to (Unix ms). Zero values cover the last 30 days.
+func (a *App) GetIncidents(id string, from, to int64) ([]domain.Incident, error) {
+	if _, ok := a.itemMap[id]; !ok {
+		return nil, fmt.Errorf("resource config not found for ID: %s", id)
+	}
+
+	now := time.Now()
+	from, to = availabilityRange(from, to, now)
+	return a.watcher.uptime.between(id, from, to, now.UnixMilli()), nil
+}
+
+// GetAvailability reports uptime and availability percentages of every
+// watched resource between from and to (Unix ms). Zero values cover the last
+// 30 days.
+func (a *App) GetAvailability(from, to int64) []domain.AvailabilityReport {
+	now := time.Now()
+	from, to = availabilityRange(from, to, now)
+
+	var reports []domain.AvailabilityReport
+	for _, r := range a.cfg.Resources {
+		if isWatched(r) {
+			reports = append(reports, a.watcher.uptime.availability(r, from, to, now))
+		}
+	}
+	return reports
+}
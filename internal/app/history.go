@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+	"zenlight-support/internal/domain"
+	"zenlight-support/internal/repository"
+)
+
+const (
+	HistoryRetention = 24 * time.Hour
+
+	// historyCapacity holds a day of samples at the default scan interval,
+	// faster bursts shorten how far back a resource can be charted
+	historyCapacity = int(HistoryRetention / ScanInterval)
+
+	maxHistoryPoints     = 1000
+	defaultHistoryRange  = time.Hour
+	defaultHistoryPoints = 360
+
+	metricsMaintainInterval = time.Minute
+)
+
+// metricHistory keeps a ring buffer of recent samples per resource. With a
+// store attached samples are also persisted, and queries reaching back past
+// the ring are answered from disk.
+type metricHistory struct {
+	mu    sync.RWMutex
+	rings map[string]*sampleRing
+	store *repository.MetricsStore
+}
+
+type sampleRing struct {
+	buf  []domain.MetricSample
+	next int
+	full bool
+}
+
+func newMetricHistory() *metricHistory {
+	return &metricHistory{rings: make(map[string]*sampleRing)}
+}
+
+func (h *metricHistory) record(s domain.ResourceStatus, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rings[s.ID]
+	if !ok {
+		r = &sampleRing{buf: make([]domain.MetricSample, historyCapacity)}
+		h.rings[s.ID] = r
+	}
+	sample := domain.NewMetricSample(s, at.UnixMilli())
+	r.buf[r.next] = sample
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+
+	if h.store != nil {
+		if err := h.store.Append(s.ID, sample); err != nil {
+			slog.Warn("Failed to persist metric sample", slog.String("id", s.ID), slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (h *metricHistory) forget(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.rings, id)
+}
+
+// attach switches the store samples are persisted to, nil stops persisting.
+// The previous store is closed.
+func (h *metricHistory) attach(store *repository.MetricsStore) {
+	h.mu.Lock()
+	old := h.store
+	h.store = store
+	h.mu.Unlock()
+
+	if old != nil && old != store {
+		if err := old.Close(); err != nil {
+			slog.Warn("Failed to close metrics store", slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (h *metricHistory) attached() *repository.MetricsStore {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.store
+}
+
+// maintain periodically flushes, compacts and prunes the attached store.
+func (h *metricHistory) maintain(ctx context.Context) {
+	ticker := time.NewTicker(metricsMaintainInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			store := h.attached()
+			if store == nil {
+				continue
+			}
+			if err := store.Maintain(now); err != nil {
+				slog.Warn("Metrics store maintenance failed", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+func (h *metricHistory) close() {
+	h.attach(nil)
+}
+
+// between returns the samples of id in [from, to), oldest first.
+func (h *metricHistory) between(id string, from, to int64) []domain.MetricSample {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	r, ok := h.rings[id]
+	if !ok {
+		return h.stored(id, from, to)
+	}
+
+	start, count := 0, r.next
+	if r.full {
+		start, count = r.next, len(r.buf)
+	}
+
+	// Anything older than the ring has to come from disk
+	var out []domain.MetricSample
+	if oldest := r.buf[start].At; count > 0 && from < oldest {
+		out = h.stored(id, from, min(to, oldest))
+	}
+	for i := range count {
+		s := r.buf[(start+i)%len(r.buf)]
+		if s.At >= from && s.At < to {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (h *metricHistory) stored(id string, from, to int64) []domain.MetricSample {
+	if h.store == nil {
+		return nil
+	}
+	samples, err := h.store.Query(id, from, to)
+	if err != nil {
+		slog.Warn("Failed to read stored metrics", slog.String("id", id), slog.String("error", err.Error()))
+		return nil
+	}
+	return samples
+}
+
+// historyRange fills in defaults: to is now, from is an hour earlier and step
+// gives about defaultHistoryPoints points. Steps are widened so a query never
+// returns more than maxHistoryPoints points.
+func historyRange(from, to, step int64, now time.Time) (int64, int64, int64) {
+	if to <= 0 {
+		to = now.UnixMilli()
+	}
+	if from <= 0 || from >= to {
+		from = to - defaultHistoryRange.Milliseconds()
+	}
+	span := to - from
+	if step <= 0 {
+		step = max(span/defaultHistoryPoints, ScanInterval.Milliseconds())
+	}
+	if span/step > maxHistoryPoints {
+		step = (span + maxHistoryPoints - 1) / maxHistoryPoints
+	}
+	return from, to, step
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+	"zenlight-support/internal/bus"
+	"zenlight-support/internal/domain"
+)
+
+const (
+	HostInterval = 5 * time.Second
+)
+
+// HostMonitor samples machine-wide metrics on a fixed interval. It is the
+// only caller of the manager's collector, whose CPU and I/O rates are measured
+// against the previous call, so readers get the latest sample instead of
+// taking their own and shortening the monitor's interval.
+type HostMonitor struct {
+	mgr    domain.ResourceManager
+	bus    *bus.Bus
+	mu     sync.Mutex
+	latest *domain.HostMetrics
+}
+
+func NewHostMonitor(mgr domain.ResourceManager, b *bus.Bus) *HostMonitor {
+	return &HostMonitor{
+		mgr: mgr,
+		bus: b,
+	}
+}
+
+func (hm *HostMonitor) Start(ctx context.Context) {
+	ticker := time.NewTicker(HostInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			metrics, err := hm.sample()
+			if err != nil {
+				slog.Warn("Failed to collect host metrics", slog.String("error", err.Error()))
+				continue
+			}
+			bus.Publish(hm.bus, TopicHost, *metrics)
+		}
+	}
+}
+
+// Latest returns the most recent sample, taking the first one if the
+// monitor has not run yet.
+func (hm *HostMonitor) Latest() (*domain.HostMetrics, error) {
+	hm.mu.Lock()
+	latest := hm.latest
+	hm.mu.Unlock()
+	if latest != nil {
+		out := *latest
+		return &out, nil
+	}
+	return hm.sample()
+}
+
+func (hm *HostMonitor) sample() (*domain.HostMetrics, error) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	metrics, err := hm.mgr.GetHostMetrics()
+	if err != nil {
+		return nil, err
+	}
+	hm.latest = metrics
+	out := *metrics
+	return &out, nil
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+	"zenlight-support/internal/bus"
+	"zenlight-support/internal/domain"
+	"zenlight-support/pkg/tail"
+
+	"github.com/google/uuid"
+)
+
+const maxAlertLines = 20
+
+// LogAlerter follows the logs of every resource with log rules and raises an
+// alert once a rule matches Threshold lines within its window.
+type LogAlerter struct {
+	mu        sync.Mutex
+	ctx       context.Context // set by Start, followers run until it is done
+	cfg       domain.Config
+	followers map[string]*logFollower // per resource ID
+	wg        sync.WaitGroup
+	bus       *bus.Bus
+}
+
+// logFollower tails the logs of one resource with the settings it was started with.
+type logFollower struct {
+	res    domain.ResourceConfig
+	cancel context.CancelFunc
+}
+
+type ruleWindow struct {
+	mu      sync.Mutex
+	rule    domain.LogRule
+	match   func(string) bool
+	matches []tail.Line
+}
+
+func NewLogAlerter(cfg domain.Config, b *bus.Bus) *LogAlerter {
+	return &LogAlerter{
+		cfg:       cfg,
+		followers: make(map[string]*logFollower),
+		bus:       b,
+	}
+}
+
+func (la *LogAlerter) Start(ctx context.Context) {
+	la.mu.Lock()
+	la.ctx = ctx
+	la.apply()
+	la.mu.Unlock()
+
+	<-ctx.Done()
+	la.mu.Lock()
+	la.ctx = nil // later updates must not start followers
+	la.mu.Unlock()
+	la.wg.Wait()
+}
+
+// Update switches to the log rules of cfg. Followers of resources whose logs
+// or rules changed are restarted, those of removed resources are stopped.
+func (la *LogAlerter) Update(cfg domain.Config) {
+	la.mu.Lock()
+	defer la.mu.Unlock()
+	la.cfg = cfg
+	if la.ctx != nil {
+		la.apply()
+	}
+}
+
+// apply brings the running followers in line with la.cfg. Callers must hold la.mu.
+func (la *LogAlerter) apply() {
+	next := make(map[string]domain.ResourceConfig)
+	for _, res := range la.cfg.Resources {
+		if len(res.Logs) > 0 && len(res.LogRules) > 0 {
+			next[res.ID] = res
+		}
+	}
+
+	for id, f := range la.followers {
+		res, ok := next[id]
+		if ok && res.Name == f.res.Name && slices.Equal(res.Logs, f.res.Logs) && reflect.DeepEqual(res.LogRules, f.res.LogRules) {
+			delete(next, id)
+			continue
+		}
+		f.cancel()
+		delete(la.followers, id)
+	}
+
+	for _, res := range la.cfg.Resources {
+		if _, ok := next[res.ID]; ok {
+			la.followers[res.ID] = la.follow(res)
+		}
+	}
+}
+
+// follow starts tailing every log of res. Callers must hold la.mu.
+func (la *LogAlerter) follow(res domain.ResourceConfig) *logFollower {
+	ctx, cancel := context.WithCancel(la.ctx)
+
+	var windows []*ruleWindow
+	for _, rule := range res.LogRules {
+		match, err := rule.Matcher()
+		if err != nil {
+			slog.Warn("Skipping invalid log rule", slog.String("resource", res.Name), slog.String("error", err.Error()))
+			continue
+		}
+		windows = append(windows, &ruleWindow{rule: rule, match: match})
+	}
+
+	for _, pattern := range res.Logs {
+		la.wg.Add(1)
+		go func() {
+			defer la.wg.Done()
+			err := tail.Follow(ctx, pattern, tail.Options{}, func(lines []tail.Line) {
+				for _, w := range windows {
+					if alert, ok := w.observe(res, lines); ok {
+						la.emit(alert)
+					}
+				}
+			})
+			if err != nil {
+				slog.Error("Log alert follower stopped", slog.String("pattern", pattern), slog.String("error", err.Error()))
+			}
+		}()
+	}
+	return &logFollower{res: res, cancel: cancel}
+}
+
+// observe adds matching lines to the window and returns an alert once the
+// threshold is reached. The window is cleared after firing so one burst
+// raises one alert.
+func (w *ruleWindow) observe(res domain.ResourceConfig, lines []tail.Line) (domain.LogAlert, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-w.rule.Window()).UnixMilli()
+
+	kept := w.matches[:0]
+	for _, l := range w.matches {
+		if l.Time >= cutoff {
+			kept = append(kept, l)
+		}
+	}
+	w.matches = kept
+
+	for _, l := range lines {
+		if w.match(l.Text) {
+			w.matches = append(w.matches, l)
+		}
+	}
+
+	if len(w.matches) < w.rule.Limit() {
+		return domain.LogAlert{}, false
+	}
+
+	alert := domain.LogAlert{
+		ID:         uuid.NewString(),
+		ResourceID: res.ID,
+		Resource:   res.Name,
+		Rule:       w.rule.Name,
+		Severity:   w.rule.Level(),
+		Count:      len(w.matches),
+		Window:     w.rule.Window().Milliseconds(),
+		Lines:      w.matches[max(0, len(w.matches)-maxAlertLines):],
+		At:         now.UnixMilli(),
+	}
+	w.matches = nil
+	return alert, true
+}
+
+func (la *LogAlerter) emit(alert domain.LogAlert) {
+	slog.Warn("Log alert raised", slog.String("resource", alert.Resource), slog.String("rule", alert.Rule), slog.Int("count", alert.Count))
+	bus.Publish(la.bus, TopicLogAlert, alert)
+}
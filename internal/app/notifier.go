@@ -0,0 +1,222 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"zenlight-support/internal/domain"
+	"zenlight-support/internal/repository"
+	"zenlight-support/pkg/notify"
+
+	"github.com/google/uuid"
+)
+
+const (
+	notifyInterval   = 10 * time.Second
+	notifyBackoff    = 30 * time.Second
+	notifyMaxBackoff = time.Hour
+)
+
+// Notifier routes notifications to the configured channels. Every delivery
+// is written to the outbox first and only removed once the channel accepted
+// it, failed ones are retried with exponential backoff.
+type Notifier struct {
+	mu       sync.RWMutex
+	channels map[string]domain.NotificationChannel
+	order    []string
+	outbox   *repository.Outbox
+	kick     chan struct{}
+	now      func() time.Time
+}
+
+func NewNotifier(cfg domain.Config, outbox *repository.Outbox) *Notifier {
+	n := &Notifier{
+		outbox: outbox,
+		kick:   make(chan struct{}, 1),
+		now:    time.Now,
+	}
+	n.Update(cfg)
+	return n
+}
+
+// Update replaces the channels. Deliveries still queued for a channel that
+// no longer exists are dropped on the next flush.
+func (n *Notifier) Update(cfg domain.Config) {
+	channels := make(map[string]domain.NotificationChannel)
+	var order []string
+	for _, ch := range cfg.Notifications {
+		if err := ch.Validate(); err != nil {
+			slog.Warn("Skipping invalid notification channel", slog.String("channel", ch.Name), slog.String("error", err.Error()))
+			continue
+		}
+		channels[ch.Name] = ch
+		order = append(order, ch.Name)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.channels, n.order = channels, order
+}
+
+func (n *Notifier) channel(name string) (domain.NotificationChannel, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	ch, ok := n.channels[name]
+	return ch, ok
+}
+
+// Notify queues n on every channel that routes it.
+func (n *Notifier) Notify(note domain.Notification) {
+	n.mu.RLock()
+	var routes []string
+	for _, name := range n.order {
+		if n.channels[name].Routes(note) {
+			routes = append(routes, name)
+		}
+	}
+	n.mu.RUnlock()
+
+	now := n.now().UnixMilli()
+	queued := false
+	for _, name := range routes {
+		d := repository.Delivery{
+			ID:           uuid.NewString(),
+			Channel:      name,
+			Notification: note,
+			NextAt:       now,
+			CreatedAt:    now,
+		}
+		if err := n.outbox.Put(d); err != nil {
+			slog.Error("Failed to queue notification", slog.String("channel", name), slog.String("error", err.Error()))
+			continue
+		}
+		queued = true
+	}
+
+	if queued {
+		select {
+		case n.kick <- struct{}{}:
+		default:
+			// A flush is already pending
+		}
+	}
+}
+
+func (n *Notifier) Start(ctx context.Context) {
+	ticker := time.NewTicker(notifyInterval)
+	defer ticker.Stop()
+
+	// Deliver whatever was left over from the last run
+	n.flush(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-n.kick:
+		}
+		n.flush(ctx)
+	}
+}
+
+func (n *Notifier) flush(ctx context.Context) {
+	pending, err := n.outbox.Pending()
+	if err != nil {
+		slog.Error("Failed to read notification outbox", slog.String("error", err.Error()))
+		return
+	}
+
+	now := n.now()
+	for _, d := range pending {
+		if ctx.Err() != nil {
+			return
+		}
+		if d.NextAt > now.UnixMilli() {
+			continue
+		}
+
+		ch, ok := n.channel(d.Channel)
+		if !ok {
+			slog.Warn("Dropping notification for removed channel", slog.String("channel", d.Channel))
+			_ = n.outbox.Remove(d.ID)
+			continue
+		}
+
+		if err := ch.Sender().Send(ctx, message(d.Notification)); err != nil {
+			d.Attempts++
+			d.LastError = err.Error()
+			backoff := min(notifyBackoff<<min(d.Attempts-1, 16), notifyMaxBackoff)
+			d.NextAt = n.now().Add(backoff).UnixMilli()
+			slog.Warn("Notification delivery failed", slog.String("channel", d.Channel), slog.Int("attempts", d.Attempts), slog.String("error", err.Error()))
+			if err := n.outbox.Put(d); err != nil {
+				slog.Error("Failed to update notification outbox", slog.String("error", err.Error()))
+			}
+			continue
+		}
+
+		if err := n.outbox.Remove(d.ID); err != nil {
+			slog.Error("Failed to remove delivered notification", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// Test sends a sample notification straight to a channel, bypassing the outbox.
+func (n *Notifier) Test(ctx context.Context, channel string) error {
+	ch, ok := n.channel(channel)
+	if !ok {
+		return fmt.Errorf("notification channel not found: %s", channel)
+	}
+	note := domain.Notification{
+		ID:       uuid.NewString(),
+		Source:   "test",
+		Severity: domain.SeverityInfo,
+		Title:    "Zenlight Support test notification",
+		Message:  fmt.Sprintf("Channel %s is set up correctly.", channel),
+		At:       n.now().UnixMilli(),
+	}
+	return ch.Sender().Send(ctx, message(note))
+}
+
+func message(note domain.Notification) notify.Message {
+	return notify.Message{Subject: note.Title, Text: note.Message, Data: note}
+}
+
+func alertNotification(a domain.Alert) domain.Notification {
+	title := fmt.Sprintf("[%s] %s: %s", a.Severity, a.Resource, a.Rule)
+	msg := fmt.Sprintf("%s is %s, last value %g.", a.Expr, a.State, a.Value)
+	if a.State == domain.AlertResolved {
+		title = fmt.Sprintf("[resolved] %s: %s", a.Resource, a.Rule)
+	}
+	return domain.Notification{
+		ID:         a.ID,
+		Source:     "alert",
+		ResourceID: a.ResourceID,
+		Resource:   a.Resource,
+		Severity:   a.Severity,
+		State:      string(a.State),
+		Title:      title,
+		Message:    msg,
+		At:         time.Now().UnixMilli(),
+	}
+}
+
+func logAlertNotification(a domain.LogAlert) domain.Notification {
+	msg := fmt.Sprintf("%d matching lines within %s.", a.Count, time.Duration(a.Window)*time.Millisecond)
+	if len(a.Lines) > 0 {
+		msg += "\n\nLast line: " + a.Lines[len(a.Lines)-1].Text
+	}
+	return domain.Notification{
+		ID:         a.ID,
+		Source:     "log-alert",
+		ResourceID: a.ResourceID,
+		Resource:   a.Resource,
+		Severity:   a.Severity,
+		State:      string(domain.AlertFiring),
+		Title:      fmt.Sprintf("[%s] %s: log rule %s", a.Severity, a.Resource, a.Rule),
+		Message:    msg,
+		At:         a.At,
+	}
+}
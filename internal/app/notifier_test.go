@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"zenlight-support/internal/domain"
+	"zenlight-support/internal/repository"
+	"zenlight-support/pkg/notify"
+)
+
+func newTestNotifier(t *testing.T, channels ...domain.NotificationChannel) (*Notifier, *repository.Outbox, *time.Time) {
+	t.Helper()
+	outbox, err := repository.OpenOutbox(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := NewNotifier(domain.Config{Notifications: channels}, outbox)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	n.now = func() time.Time { return now }
+	return n, outbox, &now
+}
+
+func webhookChannel(name, url string) domain.NotificationChannel {
+	return domain.NotificationChannel{Name: name, Kind: domain.WebhookChannel, Webhook: &notify.Webhook{URL: url}}
+}
+
+func pending(t *testing.T, outbox *repository.Outbox) []repository.Delivery {
+	t.Helper()
+	ds, err := outbox.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ds
+}
+
+func TestNotifierRetry(t *testing.T) {
+	var calls, failures atomic.Int32
+	failures.Store(2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	n, outbox, now := newTestNotifier(t, webhookChannel("hook", srv.URL))
+	n.Notify(domain.Notification{ID: "a1", Severity: domain.SeverityCritical, Title: "down"})
+	ctx := context.Background()
+
+	n.flush(ctx)
+	ds := pending(t, outbox)
+	if len(ds) != 1 || ds[0].Attempts != 1 || ds[0].LastError == "" {
+		t.Fatalf("after first failure outbox = %+v, want one delivery with 1 attempt", ds)
+	}
+	if want := now.Add(notifyBackoff).UnixMilli(); ds[0].NextAt != want {
+		t.Errorf("NextAt = %d, want %d", ds[0].NextAt, want)
+	}
+
+	// Not due yet, nothing is sent
+	n.flush(ctx)
+	if calls.Load() != 1 {
+		t.Fatalf("webhook called %d times before the backoff elapsed, want 1", calls.Load())
+	}
+
+	*now = now.Add(notifyBackoff)
+	n.flush(ctx)
+	ds = pending(t, outbox)
+	if len(ds) != 1 || ds[0].Attempts != 2 {
+		t.Fatalf("after second failure outbox = %+v, want one delivery with 2 attempts", ds)
+	}
+	if want := now.Add(2 * notifyBackoff).UnixMilli(); ds[0].NextAt != want {
+		t.Errorf("NextAt = %d, want doubled backoff %d", ds[0].NextAt, want)
+	}
+
+	*now = now.Add(2 * notifyBackoff)
+	n.flush(ctx)
+	if ds := pending(t, outbox); len(ds) != 0 {
+		t.Fatalf("outbox after delivery = %+v, want empty", ds)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("webhook called %d times, want 3", calls.Load())
+	}
+}
+
+func TestNotifierBackoffCap(t *testing.T) {
+	n, outbox, now := newTestNotifier(t, webhookChannel("hook", "http://127.0.0.1:1"))
+	if err := outbox.Put(repository.Delivery{ID: "d1", Channel: "hook", Attempts: 20}); err != nil {
+		t.Fatal(err)
+	}
+
+	n.flush(context.Background())
+	ds := pending(t, outbox)
+	if len(ds) != 1 || ds[0].Attempts != 21 {
+		t.Fatalf("outbox = %+v, want one delivery with 21 attempts", ds)
+	}
+	if want := now.Add(notifyMaxBackoff).UnixMilli(); ds[0].NextAt != want {
+		t.Errorf("NextAt = %d, want capped backoff %d", ds[0].NextAt, want)
+	}
+}
+
+func TestNotifierRouting(t *testing.T) {
+	all := webhookChannel("all", "http://127.0.0.1:1")
+	critical := webhookChannel("critical", "http://127.0.0.1:1")
+	critical.MinSeverity = domain.SeverityCritical
+	scoped := webhookChannel("scoped", "http://127.0.0.1:1")
+	scoped.Resources = []string{"Report Service"}
+	n, outbox, _ := newTestNotifier(t, all, critical, scoped)
+
+	n.Notify(domain.Notification{ID: "a1", Resource: "Other Service", Severity: domain.SeverityWarning})
+
+	ds := pending(t, outbox)
+	if len(ds) != 1 || ds[0].Channel != "all" {
+		t.Fatalf("queued %+v, want a single delivery on all", ds)
+	}
+}
+
+func TestNotifierUpdate(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	// Started without channels, as on a fresh install
+	n, outbox, _ := newTestNotifier(t)
+	n.Notify(domain.Notification{ID: "a1", Severity: domain.SeverityCritical})
+	if ds := pending(t, outbox); len(ds) != 0 {
+		t.Fatalf("queued %+v without channels", ds)
+	}
+
+	n.Update(domain.Config{Notifications: []domain.NotificationChannel{webhookChannel("hook", srv.URL)}})
+	n.Notify(domain.Notification{ID: "a2", Severity: domain.SeverityCritical})
+	n.flush(context.Background())
+	if calls.Load() != 1 {
+		t.Fatalf("webhook called %d times after adding the channel, want 1", calls.Load())
+	}
+
+	// Removing the channel drops what is still queued for it
+	if err := outbox.Put(repository.Delivery{ID: "d1", Channel: "hook"}); err != nil {
+		t.Fatal(err)
+	}
+	n.Update(domain.Config{})
+	n.flush(context.Background())
+	if ds := pending(t, outbox); len(ds) != 0 {
+		t.Fatalf("outbox = %+v, want deliveries of the removed channel dropped", ds)
+	}
+	if err := n.Test(context.Background(), "hook"); err == nil {
+		t.Error("Test() on a removed channel succeeded")
+	}
+}
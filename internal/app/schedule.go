@@ -0,0 +1,151 @@
+package app
+
+import (
+	"sync"
+	"time"
+	"zenlight-support/internal/domain"
+)
+
+const (
+	BurstInterval = 1 * time.Second
+	BurstDuration = 30 * time.Second
+
+	// Resources unchanged for this long are polled at half, then a quarter of their rate
+	StableAfter     = 2 * time.Minute
+	VeryStableAfter = 10 * time.Minute
+	MaxPollInterval = time.Minute
+
+	// DirectoryRescanInterval is the fallback walk for directories, changes
+	// reported by the file system are picked up after DirectorySettle instead
+	DirectoryRescanInterval = time.Minute
+	DirectorySettle         = time.Second
+)
+
+// pollSchedule decides when each resource is due. A resource is polled fast
+// for a short burst after it was acted on or while it is pending, and backs
+// off once it has not changed for a while.
+type pollSchedule struct {
+	mu     sync.Mutex
+	states map[string]*pollState
+}
+
+type pollState struct {
+	next       time.Time
+	burstUntil time.Time
+	lastChange time.Time
+	inFlight   chan struct{} // closed once the running probe finishes, nil when idle
+}
+
+func newPollSchedule() *pollSchedule {
+	return &pollSchedule{states: make(map[string]*pollState)}
+}
+
+func (s *pollSchedule) state(id string) *pollState {
+	st, ok := s.states[id]
+	if !ok {
+		st = &pollState{}
+		s.states[id] = st
+	}
+	return st
+}
+
+// claim reports whether id is due at now, or not already being probed when
+// forced, and marks it in flight so a slow probe is not started twice.
+func (s *pollSchedule) claim(id string, now time.Time, force bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.state(id)
+	if st.inFlight != nil || (!force && now.Before(st.next)) {
+		return false
+	}
+	st.inFlight = make(chan struct{})
+	return true
+}
+
+// wait returns a channel closed once the probe of id running now finishes,
+// or nil when none is.
+func (s *pollSchedule) wait(id string) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if st, ok := s.states[id]; ok && st.inFlight != nil {
+		return st.inFlight
+	}
+	return nil
+}
+
+func (st *pollState) release() {
+	if st.inFlight != nil {
+		close(st.inFlight)
+		st.inFlight = nil
+	}
+}
+
+// done records a finished probe and schedules the next one.
+func (s *pollSchedule) done(cfg domain.ResourceConfig, current domain.ResourceStatus, changed bool, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.state(cfg.ID)
+	st.release()
+	if changed || st.lastChange.IsZero() {
+		st.lastChange = now
+	}
+	st.next = now.Add(s.interval(cfg, st, current, now))
+}
+
+func (s *pollSchedule) interval(cfg domain.ResourceConfig, st *pollState, current domain.ResourceStatus, now time.Time) time.Duration {
+	fallback := ScanInterval
+	if cfg.Type == domain.DirectoryType {
+		fallback = DirectoryRescanInterval
+	}
+	base := cfg.PollInterval(fallback)
+
+	if now.Before(st.burstUntil) || current.Status.IsPending() {
+		return min(base, BurstInterval)
+	}
+
+	// Covers both healthy resources and ones that have been unreachable all along
+	stable := now.Sub(st.lastChange)
+	switch {
+	case stable >= VeryStableAfter:
+		return max(base, min(4*base, MaxPollInterval))
+	case stable >= StableAfter:
+		return max(base, min(2*base, MaxPollInterval))
+	}
+	return base
+}
+
+func (s *pollSchedule) forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.states[id]; ok {
+		st.release()
+	}
+	delete(s.states, id)
+}
+
+// poke brings the next poll of id forward to at most DirectorySettle from
+// now. Repeated pokes during a large copy do not postpone it further.
+func (s *pollSchedule) poke(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.state(id)
+	if due := time.Now().Add(DirectorySettle); due.Before(st.next) {
+		st.next = due
+	}
+}
+
+// burst polls id at BurstInterval for the next BurstDuration, starting right away.
+func (s *pollSchedule) burst(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	st := s.state(id)
+	st.burstUntil = now.Add(BurstDuration)
+	st.lastChange = now
+	st.next = now
+}
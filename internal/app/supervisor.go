@@ -0,0 +1,197 @@
+package app
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+	"zenlight-support/internal/bus"
+	"zenlight-support/internal/domain"
+)
+
+const restartHistoryLimit = 50
+
+type superviseState struct {
+	attempts  []time.Time
+	nextAt    time.Time
+	backoff   time.Duration
+	suspended bool
+	crashLoop bool
+	history   []domain.RestartAttempt
+}
+
+// Supervisor restarts services with a keep running policy when they stop on
+// their own, backing off exponentially and giving up once they crash-loop.
+type Supervisor struct {
+	mgr    domain.ResourceManager
+	now    func() time.Time
+	mu     sync.Mutex
+	states map[string]*superviseState
+	bus    *bus.Bus
+}
+
+func NewSupervisor(mgr domain.ResourceManager, b *bus.Bus) *Supervisor {
+	return &Supervisor{
+		mgr:    mgr,
+		now:    time.Now,
+		states: make(map[string]*superviseState),
+		bus:    b,
+	}
+}
+
+// Suspend stops supervision of id, used for stops requested by the operator.
+func (s *Supervisor) Suspend(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state(id).suspended = true
+}
+
+// Resume re-enables supervision of id and clears any crash-loop verdict.
+func (s *Supervisor) Resume(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.state(id)
+	st.suspended = false
+	st.crashLoop = false
+	st.attempts = nil
+	st.backoff = 0
+	st.nextAt = time.Time{}
+}
+
+// Forget drops all tracking of a resource that is no longer configured.
+func (s *Supervisor) Forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, id)
+}
+
+// Reset clears the restart tracking of a resource whose config changed, so
+// crashes counted against the old settings do not carry over. A suspension is
+// kept, the operator stopped the service on purpose.
+func (s *Supervisor) Reset(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.states[id]; ok {
+		s.states[id] = &superviseState{suspended: old.suspended, history: old.history}
+	}
+}
+
+func (s *Supervisor) History(id string) []domain.RestartAttempt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.states[id]
+	if !ok {
+		return nil
+	}
+	return append([]domain.RestartAttempt(nil), st.history...)
+}
+
+// Observe is called by the watcher with the latest state of a service. It
+// returns the restart to carry out, or nil, so the watcher can run it after
+// releasing its own locks.
+func (s *Supervisor) Observe(cfg domain.ResourceConfig, status domain.Status) func() {
+	policy := cfg.KeepRunning
+	if policy == nil || !policy.Enabled || cfg.Type != domain.ServiceType {
+		return nil
+	}
+
+	now := s.now()
+
+	s.mu.Lock()
+	st := s.state(cfg.ID)
+	if status != domain.STOPPED {
+		// Forget old crashes once the service has been stable for a whole window
+		if len(st.attempts) > 0 && now.Sub(st.attempts[len(st.attempts)-1]) > policy.Window() {
+			st.attempts = nil
+			st.backoff = 0
+		}
+		s.mu.Unlock()
+		return nil
+	}
+	if st.suspended || st.crashLoop || now.Before(st.nextAt) {
+		s.mu.Unlock()
+		return nil
+	}
+
+	st.attempts = pruneBefore(st.attempts, now.Add(-policy.Window()))
+	attempt := domain.RestartAttempt{
+		ID:      cfg.ID,
+		Name:    cfg.Name,
+		At:      now.UnixMilli(),
+		Attempt: len(st.attempts) + 1,
+	}
+
+	if len(st.attempts) >= policy.Limit() {
+		st.crashLoop = true
+		attempt.Attempt = len(st.attempts)
+		attempt.CrashLoop = true
+		attempt.Error = "restart limit reached, giving up"
+		s.record(st, attempt)
+		s.mu.Unlock()
+		return func() {
+			slog.Error("Service is crash-looping, giving up", slog.String("id", cfg.ID), slog.String("name", cfg.Name))
+			s.emit(attempt)
+		}
+	}
+
+	if st.backoff == 0 {
+		st.backoff = policy.Backoff()
+	} else {
+		st.backoff = min(st.backoff*2, policy.MaxBackoff())
+	}
+	st.attempts = append(st.attempts, now)
+	st.nextAt = now.Add(st.backoff)
+	attempt.Backoff = st.backoff.Milliseconds()
+	s.mu.Unlock()
+
+	return func() {
+		if err := s.mgr.StartService(cfg.ServiceName); err != nil {
+			attempt.Error = err.Error()
+			slog.Warn("Automatic restart failed", slog.String("name", cfg.Name), slog.String("error", err.Error()))
+		} else {
+			attempt.Success = true
+			slog.Info("Service restarted automatically", slog.String("name", cfg.Name), slog.Int("attempt", attempt.Attempt))
+		}
+
+		s.mu.Lock()
+		current := s.states[cfg.ID] == st
+		if current {
+			s.record(st, attempt)
+		}
+		s.mu.Unlock()
+		if current {
+			// Not announced for a resource removed or changed in the meantime
+			s.emit(attempt)
+		}
+	}
+}
+
+// state returns the tracking state for id. Callers must hold s.mu.
+func (s *Supervisor) state(id string) *superviseState {
+	st, ok := s.states[id]
+	if !ok {
+		st = &superviseState{}
+		s.states[id] = st
+	}
+	return st
+}
+
+func (s *Supervisor) record(st *superviseState, attempt domain.RestartAttempt) {
+	st.history = append(st.history, attempt)
+	if len(st.history) > restartHistoryLimit {
+		st.history = st.history[len(st.history)-restartHistoryLimit:]
+	}
+}
+
+func (s *Supervisor) emit(attempt domain.RestartAttempt) {
+	bus.Publish(s.bus, TopicRestart, attempt)
+}
+
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	kept := times[:0]
+	for _, t := range times {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
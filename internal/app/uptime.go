@@ -0,0 +1,418 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"zenlight-support/internal/domain"
+	"zenlight-support/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	incidentsFile = "incidents.jsonl"
+
+	// plannedGrace is how long a stop requested from the app may take to show
+	// up as downtime before the request no longer explains it
+	plannedGrace = 2 * time.Minute
+
+	defaultAvailabilityRange = 30 * 24 * time.Hour
+
+	// heartbeatInterval bounds how much of a crashed run is counted as
+	// monitored, compactInterval how often incidents.jsonl is rewritten
+	heartbeatInterval = time.Minute
+	compactInterval   = time.Hour
+)
+
+type plannedStop struct {
+	cause string
+	until time.Time
+}
+
+// uptimeTracker turns watcher transitions into downtime incidents. An
+// incident opens when a resource goes down and closes when it is back up.
+// Stops requested from the app are recorded as planned. Runs of the app are
+// recorded as sessions, time outside them was not observed.
+type uptimeTracker struct {
+	mu        sync.Mutex
+	incidents []domain.Incident
+	open      map[string]int // index into incidents per resource
+	planned   map[string]plannedStop
+	watched   map[string]int64 // when each resource was first observed, Unix ms
+	sessions  []domain.Session
+	session   int // index of the running session, -1 when not running
+	retention time.Duration
+	store     *repository.IncidentStore
+}
+
+func newUptimeTracker() *uptimeTracker {
+	return &uptimeTracker{
+		open:      make(map[string]int),
+		planned:   make(map[string]plannedStop),
+		watched:   make(map[string]int64),
+		session:   -1,
+		retention: defaultAvailabilityRange,
+	}
+}
+
+// incidentRetention keeps incidents as long as metrics, but at least for the
+// default report range.
+func incidentRetention(metrics *domain.MetricsConfig) time.Duration {
+	return max(metrics.Retention(), defaultAvailabilityRange)
+}
+
+// attach loads past incidents and must be called before start.
+// Incidents left open by the last run stay open until the resource is seen up.
+func (u *uptimeTracker) attach(store *repository.IncidentStore) error {
+	log, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.store = store
+	u.incidents = log.Incidents
+	u.watched = log.Watched
+	u.sessions = log.Sessions
+	u.reindex()
+	return nil
+}
+
+// configure sets how long incidents and sessions are kept once they ended.
+func (u *uptimeTracker) configure(retention time.Duration) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.retention = retention
+}
+
+// start records the beginning of a run of the app.
+func (u *uptimeTracker) start(at time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.session = len(u.sessions)
+	u.sessions = append(u.sessions, domain.Session{ID: uuid.NewString(), Start: at.UnixMilli(), End: at.UnixMilli()})
+	u.persistSession()
+}
+
+// stop records the end of the run, the time up to the next start is unknown.
+func (u *uptimeTracker) stop(at time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.session < 0 {
+		return
+	}
+	u.sessions[u.session].End = at.UnixMilli()
+	u.persistSession()
+	u.session = -1
+}
+
+// maintain writes heartbeats so a crashed run ends near where it stopped,
+// and drops expired incidents from the file until ctx is done.
+func (u *uptimeTracker) maintain(ctx context.Context) {
+	u.compact(time.Now())
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	compaction := time.NewTicker(compactInterval)
+	defer compaction.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-heartbeat.C:
+			u.mu.Lock()
+			if u.session >= 0 {
+				u.sessions[u.session].End = now.UnixMilli()
+				u.persistSession()
+			}
+			u.mu.Unlock()
+		case now := <-compaction.C:
+			u.compact(now)
+		}
+	}
+}
+
+// compact forgets incidents and sessions that ended before the retention
+// period and rewrites the file with what is left.
+func (u *uptimeTracker) compact(now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	cutoff := now.Add(-u.retention).UnixMilli()
+
+	incidents := u.incidents[:0:0]
+	for _, inc := range u.incidents {
+		if inc.End == 0 || inc.End >= cutoff {
+			incidents = append(incidents, inc)
+		}
+	}
+	var sessions []domain.Session
+	current := -1
+	for i, session := range u.sessions {
+		if i == u.session {
+			current = len(sessions)
+		} else if session.End < cutoff {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	for id, since := range u.watched {
+		u.watched[id] = max(since, cutoff)
+	}
+
+	u.incidents = incidents
+	u.sessions = sessions
+	u.session = current
+	u.reindex()
+
+	if u.store == nil {
+		return
+	}
+	log := &repository.IncidentLog{Incidents: u.incidents, Watched: u.watched, Sessions: u.sessions}
+	if err := u.store.Compact(log); err != nil {
+		slog.Warn("Failed to compact incident log", slog.String("error", err.Error()))
+	}
+}
+
+// reindex rebuilds the open incidents after u.incidents was replaced. Callers
+// must hold u.mu.
+func (u *uptimeTracker) reindex() {
+	clear(u.open)
+	for i, inc := range u.incidents {
+		if inc.End == 0 {
+			u.open[inc.ResourceID] = i
+		}
+	}
+}
+
+// plan marks the next outage of id as intended by the operator.
+func (u *uptimeTracker) plan(id, cause string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.planned[id] = plannedStop{cause: cause, until: time.Now().Add(plannedGrace)}
+}
+
+// unplan drops the mark set by plan when the stop did not go through.
+func (u *uptimeTracker) unplan(id string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.planned, id)
+}
+
+func (u *uptimeTracker) observe(cfg domain.ResourceConfig, st domain.ResourceStatus, at time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if _, ok := u.watched[cfg.ID]; !ok {
+		u.watched[cfg.ID] = at.UnixMilli()
+		if u.store != nil {
+			if err := u.store.MarkWatched(cfg.ID, at.UnixMilli()); err != nil {
+				slog.Warn("Failed to persist watch start", slog.String("id", cfg.ID), slog.String("error", err.Error()))
+			}
+		}
+	}
+
+	down, known := isDown(cfg, st)
+	if !known {
+		// A failed probe says nothing about the resource, leave any incident as it is
+		return
+	}
+
+	i, open := u.open[cfg.ID]
+	switch {
+	case open && !down:
+		u.incidents[i].End = at.UnixMilli()
+		delete(u.open, cfg.ID)
+		u.persist(u.incidents[i])
+
+	case !open && down:
+		inc := domain.Incident{
+			ID:         uuid.NewString(),
+			ResourceID: cfg.ID,
+			Resource:   cfg.Name,
+			Start:      at.UnixMilli(),
+			Cause:      downCause(st),
+		}
+		if p, ok := u.planned[cfg.ID]; ok && at.Before(p.until) {
+			inc.Planned = true
+			inc.Cause = p.cause
+		}
+		delete(u.planned, cfg.ID)
+		u.open[cfg.ID] = len(u.incidents)
+		u.incidents = append(u.incidents, inc)
+		u.persist(inc)
+		slog.Info("Downtime started", slog.String("resource", cfg.Name), slog.Bool("planned", inc.Planned), slog.String("cause", inc.Cause))
+	}
+}
+
+// forget closes the open incident of a resource that is no longer configured.
+func (u *uptimeTracker) forget(id string, at time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if i, ok := u.open[id]; ok {
+		u.incidents[i].End = at.UnixMilli()
+		u.persist(u.incidents[i])
+	}
+	delete(u.open, id)
+	delete(u.planned, id)
+
+	// Re-adding the resource later starts a new watch period
+	if _, ok := u.watched[id]; ok && u.store != nil {
+		if err := u.store.MarkWatched(id, 0); err != nil {
+			slog.Warn("Failed to persist watch end", slog.String("id", id), slog.String("error", err.Error()))
+		}
+	}
+	delete(u.watched, id)
+}
+
+// persistSession writes the running session. Callers must hold u.mu.
+func (u *uptimeTracker) persistSession() {
+	if u.store == nil {
+		return
+	}
+	if err := u.store.MarkSession(u.sessions[u.session]); err != nil {
+		slog.Warn("Failed to persist app session", slog.String("error", err.Error()))
+	}
+}
+
+func (u *uptimeTracker) persist(inc domain.Incident) {
+	if u.store == nil {
+		return
+	}
+	if err := u.store.Append(inc); err != nil {
+		slog.Warn("Failed to persist incident", slog.String("id", inc.ResourceID), slog.String("error", err.Error()))
+	}
+}
+
+// between returns the incidents of id overlapping [from, to), oldest first.
+func (u *uptimeTracker) between(id string, from, to, now int64) []domain.Incident {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var out []domain.Incident
+	for _, inc := range u.incidents {
+		if inc.ResourceID == id && inc.Overlap(from, to, now) > 0 {
+			out = append(out, inc)
+		}
+	}
+	return out
+}
+
+// availability sums the downtime of id within [from, to). Only the time the
+// resource was watched is counted: the period starts no earlier than its
+// first observation and ends no later than now, and the time the app was not
+// running is reported as unknown instead.
+func (u *uptimeTracker) availability(cfg domain.ResourceConfig, from, to int64, now time.Time) domain.AvailabilityReport {
+	nowMs := now.UnixMilli()
+
+	u.mu.Lock()
+	since, ok := u.watched[cfg.ID]
+	u.mu.Unlock()
+	if !ok {
+		return domain.AvailabilityReport{ResourceID: cfg.ID, Resource: cfg.Name, From: from, To: to}
+	}
+	from = max(from, since)
+	to = max(from, to)
+	end := max(min(to, nowMs), from)
+
+	report := domain.AvailabilityReport{
+		ResourceID: cfg.ID,
+		Resource:   cfg.Name,
+		From:       from,
+		To:         to,
+		Incidents:  u.between(cfg.ID, from, to, nowMs),
+	}
+
+	spans := u.running(from, end, nowMs)
+	for _, sp := range spans {
+		report.Monitored += sp.to - sp.from
+	}
+	report.Unknown = end - from - report.Monitored
+
+	for _, inc := range report.Incidents {
+		for _, sp := range spans {
+			if inc.Planned {
+				report.PlannedDowntime += inc.Overlap(sp.from, sp.to, nowMs)
+			} else {
+				report.UnplannedDowntime += inc.Overlap(sp.from, sp.to, nowMs)
+			}
+		}
+	}
+
+	report.Uptime = percentUp(report.Monitored, report.PlannedDowntime+report.UnplannedDowntime)
+	report.Availability = percentUp(report.Monitored-report.PlannedDowntime, report.UnplannedDowntime)
+	return report
+}
+
+type span struct {
+	from, to int64
+}
+
+// running returns the parts of [from, to) during which the app was running.
+// The running session lasts until now.
+func (u *uptimeTracker) running(from, to, now int64) []span {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var spans []span
+	for i, session := range u.sessions {
+		end := session.End
+		if i == u.session {
+			end = now
+		}
+		sp := span{from: max(session.Start, from), to: min(end, to)}
+		if sp.to > sp.from {
+			spans = append(spans, sp)
+		}
+	}
+	return spans
+}
+
+func percentUp(period, down int64) float64 {
+	if period <= 0 {
+		return 0
+	}
+	return float64(period-down) / float64(period) * 100
+}
+
+// isDown treats a resource as down once it has stopped, until it is running
+// again. A failed probe of a service or process is not known either way,
+// while one of a directory, volume or endpoint means it is unreachable.
+func isDown(cfg domain.ResourceConfig, st domain.ResourceStatus) (down, known bool) {
+	switch st.Status {
+	case domain.STOPPED, domain.START_PENDING:
+		return true, true
+	case domain.UNKNOWN:
+		if st.Error == "" || hasProcess(cfg) {
+			return false, false
+		}
+		return true, true
+	}
+	return false, true
+}
+
+func downCause(st domain.ResourceStatus) string {
+	if st.Error != "" {
+		return st.Error
+	}
+	return fmt.Sprintf("status changed to %s", st.Status)
+}
+
+// availabilityRange defaults to the last 30 days ending now.
+func availabilityRange(from, to int64, now time.Time) (int64, int64) {
+	if to <= 0 {
+		to = now.UnixMilli()
+	}
+	if from <= 0 || from >= to {
+		from = to - defaultAvailabilityRange.Milliseconds()
+	}
+	return from, to
+}
@@ -2,36 +2,62 @@ package app
 
 import (
 	"context"
+	"reflect"
+	"slices"
 	"sync"
 	"time"
+	"zenlight-support/internal/bus"
 	"zenlight-support/internal/domain"
+	"zenlight-support/pkg/health"
 )
 
 const (
 	ScanInterval = 5 * time.Second
+
+	// tickInterval is how often the watcher checks which resources are due
+	tickInterval = 500 * time.Millisecond
 )
 
 type ServiceWatcher struct {
+	mu         sync.RWMutex
 	cfg        domain.Config
+	gens       map[string]uint64 // config generation per resource, bumped when it changes
+	gen        uint64
 	mgr        domain.ResourceManager
-	lastStatus sync.Map
-	updates    chan []domain.ResourceStatus
+	lastStatus sync.Map // last reported status per resource
+	latest     sync.Map // result of the most recent probe per resource
+	bus        *bus.Bus
+	supervisor *Supervisor
+	schedule   *pollSchedule
+	dirs       *dirNotifier
+	history    *metricHistory
+	alerts     *AlertEngine
+	uptime     *uptimeTracker
 }
 
-func NewServiceWatcher(cfg domain.Config, mgr domain.ResourceManager) *ServiceWatcher {
+func NewServiceWatcher(cfg domain.Config, mgr domain.ResourceManager, b *bus.Bus) *ServiceWatcher {
+	gens := make(map[string]uint64, len(cfg.Resources))
+	for _, r := range cfg.Resources {
+		gens[r.ID] = 0
+	}
 	return &ServiceWatcher{
-		cfg:     cfg,
-		mgr:     mgr,
-		updates: make(chan []domain.ResourceStatus, 10),
+		cfg:        cloneResources(cfg),
+		gens:       gens,
+		mgr:        mgr,
+		bus:        b,
+		supervisor: NewSupervisor(mgr, b),
+		schedule:   newPollSchedule(),
+		dirs:       newDirNotifier(cfg),
+		history:    newMetricHistory(),
+		alerts:     NewAlertEngine(cfg, b),
+		uptime:     newUptimeTracker(),
 	}
 }
 
-func (sw *ServiceWatcher) Updates() <-chan []domain.ResourceStatus {
-	return sw.updates
-}
-
 func (sw *ServiceWatcher) Start(ctx context.Context) {
-	ticket := time.NewTicker(ScanInterval)
+	go sw.dirs.run(ctx, sw.schedule.poke)
+
+	ticket := time.NewTicker(tickInterval)
 	defer ticket.Stop()
 
 	for {
@@ -44,54 +70,284 @@ func (sw *ServiceWatcher) Start(ctx context.Context) {
 	}
 }
 
+// Update swaps in a new configuration at runtime. Removed resources are
+// forgotten, changed ones are re-probed on the next tick with their new
+// settings and added ones start being watched. It returns what changed.
+// Probes still running against the old settings are discarded when they
+// finish, see check.
+func (sw *ServiceWatcher) Update(cfg domain.Config) domain.ConfigChange {
+	cfg = cloneResources(cfg)
+
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	old := sw.cfg
+	sw.cfg = cfg
+	sw.gen++
+
+	var change domain.ConfigChange
+	next := make(map[string]domain.ResourceConfig, len(cfg.Resources))
+	for _, r := range cfg.Resources {
+		next[r.ID] = r
+	}
+	for _, r := range old.Resources {
+		updated, ok := next[r.ID]
+		delete(next, r.ID)
+		switch {
+		case !ok:
+			sw.lastStatus.Delete(r.ID)
+			sw.latest.Delete(r.ID)
+			sw.schedule.forget(r.ID)
+			sw.supervisor.Forget(r.ID)
+			sw.history.forget(r.ID)
+			sw.uptime.forget(r.ID, time.Now())
+			delete(sw.gens, r.ID)
+			change.Removed = append(change.Removed, r.ID)
+		case !reflect.DeepEqual(r, updated):
+			sw.lastStatus.Delete(r.ID)
+			sw.latest.Delete(r.ID)
+			sw.schedule.forget(r.ID)
+			sw.supervisor.Reset(r.ID)
+			sw.gens[r.ID] = sw.gen
+			change.Updated = append(change.Updated, r.ID)
+		}
+	}
+	for _, r := range cfg.Resources {
+		if _, ok := next[r.ID]; ok {
+			sw.gens[r.ID] = sw.gen
+			change.Added = append(change.Added, r.ID)
+		}
+	}
+
+	sw.dirs.update(cfg)
+	sw.alerts.Update(cfg)
+	return change
+}
+
+// cloneResources keeps callers that edit their slice in place from racing the tick.
+func cloneResources(cfg domain.Config) domain.Config {
+	cfg.Resources = slices.Clone(cfg.Resources)
+	return cfg
+}
+
+// Burst polls the resource every second for a short while, used after it was
+// started, stopped or installed so the UI follows the transition closely.
+func (sw *ServiceWatcher) Burst(id string) {
+	sw.schedule.burst(id)
+}
+
 func (sw *ServiceWatcher) tick() {
-	var changed []domain.ResourceStatus
-	var mu sync.Mutex
-	var wg sync.WaitGroup
+	sw.run(false)
+}
 
-	for _, svcCfg := range sw.cfg.Resources {
-		if svcCfg.Type != domain.ServiceType {
+// Snapshot returns the latest known status of every configured resource in
+// config order. Resources that were never probed are reported as UNKNOWN.
+// With refresh set every watched resource is probed first, and those that
+// already had a probe running are waited for instead.
+func (sw *ServiceWatcher) Snapshot(refresh bool) []domain.ResourceStatus {
+	sw.mu.RLock()
+	resources := sw.cfg.Resources
+	sw.mu.RUnlock()
+
+	if refresh {
+		sw.run(true).Wait()
+		for _, r := range resources {
+			if ch := sw.schedule.wait(r.ID); ch != nil {
+				<-ch
+			}
+		}
+	}
+
+	now := time.Now().UnixMilli()
+	out := make([]domain.ResourceStatus, 0, len(resources))
+	for _, r := range resources {
+		val, ok := sw.latest.Load(r.ID)
+		if !ok {
+			out = append(out, domain.ResourceStatus{ID: r.ID, Status: domain.UNKNOWN})
 			continue
 		}
+		st := val.(domain.ResourceStatus)
+		st.Elapsed = now - st.Since
+		if last, ok := sw.lastStatus.Load(r.ID); ok {
+			st.ChangedAt = last.(domain.ResourceStatus).CheckedAt
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// run starts a probe for every resource that is due, or all watched ones when
+// forced. Each probe publishes its own change so a slow directory walk or
+// health check does not hold back the others; the returned group lets
+// callers that need the results wait for the probes started here.
+func (sw *ServiceWatcher) run(force bool) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	now := time.Now()
 
+	sw.mu.RLock()
+	resources := sw.cfg.Resources
+	gens := make(map[string]uint64, len(resources))
+	for _, r := range resources {
+		gens[r.ID] = sw.gens[r.ID]
+	}
+	sw.mu.RUnlock()
+
+	for _, svcCfg := range resources {
+		if !isWatched(svcCfg) || !sw.schedule.claim(svcCfg.ID, now, force) {
+			continue
+		}
 		wg.Add(1)
-		go func(cfg domain.ResourceConfig) {
+		go func() {
 			defer wg.Done()
+			sw.check(svcCfg, gens[svcCfg.ID], now)
+		}()
+	}
+	return &wg
+}
 
-			status, err := sw.mgr.GetResourceState(cfg.ServiceName)
-			if err != nil {
-				status = domain.STOPPED
-			}
+// check probes one resource and records and publishes the result. gen is the
+// config generation the probe was started with.
+func (sw *ServiceWatcher) check(cfg domain.ResourceConfig, gen uint64, now time.Time) {
+	status, metrics, err := sw.probe(cfg)
 
-			current := domain.ResourceStatus{
-				ID:     cfg.ID,
-				Status: status,
+	var result *health.Result
+	if cfg.HealthCheck != nil {
+		r := health.Run(context.Background(), *cfg.HealthCheck)
+		result = &r
+		if !hasProcess(cfg) {
+			status = domain.STOPPED
+			if r.Up {
+				status = domain.RUNNING
 			}
+		}
+	}
 
-			if status == domain.RUNNING {
-				if metrics, err := sw.mgr.GetServiceMetrics(cfg.ServiceName); err == nil && metrics != nil {
-					current.Metrics = metrics
-				}
-			}
+	current := domain.ResourceStatus{
+		ID:        cfg.ID,
+		Status:    status,
+		Since:     now.UnixMilli(),
+		Health:    result,
+		CheckedAt: now.UnixMilli(),
+	}
+	if err != nil {
+		current.Error = err.Error()
+	} else if result != nil && result.Error != "" {
+		current.Error = result.Error
+	}
+	if val, ok := sw.lastStatus.Load(cfg.ID); ok {
+		if old := val.(domain.ResourceStatus); old.Status == status {
+			current.Since = old.Since
+		}
+	}
+	current.Elapsed = now.UnixMilli() - current.Since
+	current.Metrics = metrics
 
-			if sw.hasChanged(cfg.ID, current) {
-				mu.Lock()
-				changed = append(changed, current)
-				mu.Unlock()
-				sw.lastStatus.Store(cfg.ID, current)
-			}
-		}(svcCfg)
+	if cfg.Type == domain.VolumeType && metrics != nil {
+		current.Severity = cfg.Volume.Evaluate(metrics.UsedPercent)
 	}
 
-	wg.Wait()
+	// Update holds the write lock while it forgets a resource, so a result
+	// recorded here cannot bring back the entries it cleared
+	sw.mu.RLock()
+	if latestGen, ok := sw.gens[cfg.ID]; !ok || latestGen != gen {
+		// Removed or changed while the probe was running, Update already
+		// released its schedule
+		sw.mu.RUnlock()
+		return
+	}
 
-	if len(changed) > 0 {
-		select {
-		case sw.updates <- changed:
-		default:
-			// Skip if channel is full to avoid blocking
+	if cfg.Type == domain.DirectoryType && err == nil {
+		sw.dirs.ensure(cfg.Path)
+	}
+	var restart func()
+	if cfg.Type == domain.ServiceType {
+		restart = sw.supervisor.Observe(cfg, status)
+	}
+
+	sw.latest.Store(cfg.ID, current)
+	sw.history.record(current, now)
+	alerts := sw.alerts.Evaluate(cfg, current)
+	sw.uptime.observe(cfg, current, now)
+
+	isChanged := sw.hasChanged(cfg.ID, current)
+	if isChanged {
+		sw.lastStatus.Store(cfg.ID, current)
+	}
+	sw.schedule.done(cfg, current, isChanged, time.Now())
+	sw.mu.RUnlock()
+
+	// Subscribers may block, publish only once the lock is released
+	if restart != nil {
+		restart()
+	}
+	sw.alerts.Publish(alerts)
+	if isChanged {
+		bus.Publish(sw.bus, TopicStatus, []domain.ResourceStatus{current})
+	}
+}
+
+func isWatched(cfg domain.ResourceConfig) bool {
+	switch cfg.Type {
+	case domain.ServiceType, domain.ProcessType, domain.EndpointType, domain.VolumeType, domain.DirectoryType:
+		return true
+	}
+	return cfg.HealthCheck != nil
+}
+
+func hasProcess(cfg domain.ResourceConfig) bool {
+	return cfg.Type == domain.ServiceType || cfg.Type == domain.ProcessType
+}
+
+// probe reads the state and, when alive, the metrics of a watched resource.
+// Endpoints take their state from the health check instead. It has no side
+// effects, check acts on the result once it knows the config is still current.
+func (sw *ServiceWatcher) probe(cfg domain.ResourceConfig) (domain.Status, *domain.ResourceMetrics, error) {
+	if cfg.Type == domain.VolumeType {
+		metrics, err := sw.mgr.GetVolumeMetrics(cfg.Path)
+		if err != nil {
+			return domain.UNKNOWN, nil, err
+		}
+		return domain.RUNNING, metrics, nil
+	}
+
+	if cfg.Type == domain.DirectoryType {
+		metrics, err := sw.mgr.GetDirectoryMetrics(cfg.Path)
+		if err != nil {
+			return domain.UNKNOWN, nil, err
+		}
+		return domain.RUNNING, metrics, nil
+	}
+
+	if !hasProcess(cfg) {
+		return domain.UNKNOWN, nil, nil
+	}
+
+	if cfg.Type == domain.ProcessType {
+		if cfg.Process == nil {
+			return domain.UNKNOWN, nil, nil
+		}
+		metrics, err := sw.mgr.GetProcessMetrics(*cfg.Process)
+		if err != nil {
+			return domain.UNKNOWN, nil, err
+		}
+		if metrics == nil {
+			return domain.STOPPED, nil, nil
 		}
+		return domain.RUNNING, metrics, nil
+	}
+
+	status, err := sw.mgr.GetResourceState(cfg.ServiceName)
+	if err != nil {
+		// Could not ask, e.g. access denied, which says nothing about the service itself
+		status = domain.UNKNOWN
+	}
+	if err != nil || !status.IsActive() {
+		return status, nil, err
 	}
+	metrics, err := sw.mgr.GetServiceMetrics(cfg.ServiceName)
+	if err != nil || metrics == nil {
+		return status, nil, err
+	}
+	return status, metrics, nil
 }
 
 func (sw *ServiceWatcher) hasChanged(id string, current domain.ResourceStatus) bool {
@@ -101,8 +357,23 @@ func (sw *ServiceWatcher) hasChanged(id string, current domain.ResourceStatus) b
 	}
 	old := val.(domain.ResourceStatus)
 
-	// Check status
-	if old.Status != current.Status {
+	// Check status, pending states are always reported so the UI can show how long they last
+	if old.Status != current.Status || current.Status.IsPending() {
+		return true
+	}
+
+	// Check threshold verdict and probe error
+	if old.Severity != current.Severity || old.Error != current.Error {
+		return true
+	}
+
+	// Check health check outcome
+	if (old.Health == nil) != (current.Health == nil) {
+		return true
+	}
+	if old.Health != nil && (old.Health.Up != current.Health.Up ||
+		old.Health.StatusCode != current.Health.StatusCode ||
+		old.Health.Error != current.Health.Error) {
 		return true
 	}
 
@@ -125,8 +396,45 @@ func (sw *ServiceWatcher) hasChanged(id string, current domain.ResourceStatus) b
 		return true
 	}
 
-	// Check PID
-	if old.Metrics.PID != current.Metrics.PID {
+	// Check volume usage
+	const volumeThreshold = 100 * 1024 * 1024 // 100 MB
+	if absDiffUint64(old.Metrics.VolumeUsed, current.Metrics.VolumeUsed) > volumeThreshold {
+		return true
+	}
+
+	// Check directory contents
+	if old.Metrics.TotalSize != current.Metrics.TotalSize ||
+		old.Metrics.FileCount != current.Metrics.FileCount ||
+		old.Metrics.LastModified != current.Metrics.LastModified {
+		return true
+	}
+
+	// Check process tree totals
+	if old.Metrics.ChildCount != current.Metrics.ChildCount {
+		return true
+	}
+	if absDiffFloat(old.Metrics.TreeCPUUsage, current.Metrics.TreeCPUUsage) > cpuThreshold {
+		return true
+	}
+	if absDiffUint64(old.Metrics.TreeMemUsage, current.Metrics.TreeMemUsage) > memThreshold {
+		return true
+	}
+
+	// Check listening ports, threads and handles
+	if !slices.Equal(old.Metrics.ListenPorts, current.Metrics.ListenPorts) {
+		return true
+	}
+	const threadThreshold = 5
+	const handleThreshold = 50
+	if absDiffInt32(old.Metrics.Threads, current.Metrics.Threads) > threadThreshold {
+		return true
+	}
+	if absDiffInt32(old.Metrics.Handles, current.Metrics.Handles) > handleThreshold {
+		return true
+	}
+
+	// Check PID and the number of matched process instances
+	if old.Metrics.PID != current.Metrics.PID || len(old.Metrics.PIDs) != len(current.Metrics.PIDs) {
 		return true
 	}
 
@@ -140,6 +448,13 @@ func absDiffFloat(a, b float64) float64 {
 	return b - a
 }
 
+func absDiffInt32(a, b int32) int32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
 func absDiffUint64(a, b uint64) uint64 {
 	if a > b {
 		return a - b
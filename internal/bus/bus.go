@@ -0,0 +1,157 @@
+package bus
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Policy decides what happens when a subscriber's buffer is full.
+type Policy int
+
+const (
+	// DropOldest discards the oldest buffered event, subscribers always see the latest state
+	DropOldest Policy = iota
+	// DropNewest discards the event being published
+	DropNewest
+	// Block makes the publisher wait, nothing is lost but a slow subscriber stalls publishers
+	Block
+)
+
+const defaultBuffer = 16
+
+type Options struct {
+	Buffer int
+	Policy Policy
+}
+
+// Topic names a stream of events of type T.
+type Topic[T any] struct {
+	Name string
+}
+
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{Name: name}
+}
+
+// Bus fans events out to every subscriber of their topic. Each subscriber
+// has its own buffer so one slow consumer does not affect the others.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[string][]sink
+}
+
+type sink interface {
+	deliver(v any)
+}
+
+func New() *Bus {
+	return &Bus{subs: make(map[string][]sink)}
+}
+
+type Subscription[T any] struct {
+	ch      chan T
+	policy  Policy
+	bus     *Bus
+	topic   string
+	mu      sync.Mutex
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Uint64
+}
+
+func Subscribe[T any](b *Bus, t Topic[T], opts Options) *Subscription[T] {
+	if opts.Buffer <= 0 {
+		opts.Buffer = defaultBuffer
+	}
+	s := &Subscription[T]{
+		ch:     make(chan T, opts.Buffer),
+		policy: opts.Policy,
+		bus:    b,
+		topic:  t.Name,
+		done:   make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	b.subs[t.Name] = append(b.subs[t.Name], s)
+	b.mu.Unlock()
+	return s
+}
+
+// Publish delivers v to every current subscriber of t.
+func Publish[T any](b *Bus, t Topic[T], v T) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, s := range b.subs[t.Name] {
+		s.deliver(v)
+	}
+}
+
+// C is closed once the subscription is closed.
+func (s *Subscription[T]) C() <-chan T {
+	return s.ch
+}
+
+// Dropped counts events lost to the overflow policy.
+func (s *Subscription[T]) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+func (s *Subscription[T]) Close() {
+	s.once.Do(func() {
+		// Release publishers blocked on this subscriber before taking the write lock
+		close(s.done)
+
+		s.bus.mu.Lock()
+		subs := s.bus.subs[s.topic]
+		for i, other := range subs {
+			if other == sink(s) {
+				s.bus.subs[s.topic] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+		s.bus.mu.Unlock()
+
+		close(s.ch)
+	})
+}
+
+func (s *Subscription[T]) deliver(v any) {
+	event := v.(T)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	switch s.policy {
+	case Block:
+		select {
+		case s.ch <- event:
+		case <-s.done:
+		}
+	case DropNewest:
+		select {
+		case s.ch <- event:
+		default:
+			s.dropped.Add(1)
+		}
+	default:
+		for {
+			select {
+			case s.ch <- event:
+				return
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	}
+}
@@ -96,6 +96,10 @@ func ensureConfig(repo *repository.YamlConfigRepository, appVer string) (*domain
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	if err := cfg.ValidateDependencies(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
 	isOutdated, err := checkIsOutdated(cfg.Version, appVer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compare config versions: %w", err)
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"zenlight-support/pkg/tail"
+)
+
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Rank orders severities, an unknown or empty severity ranks lowest.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityInfo:
+		return 1
+	case SeverityWarning:
+		return 2
+	case SeverityCritical:
+		return 3
+	}
+	return 0
+}
+
+// LogRule raises an alert when Threshold lines matching Pattern or Keyword
+// appear in a resource's logs within the window.
+type LogRule struct {
+	Name          string   `json:"name" yaml:"name"`
+	Pattern       string   `json:"pattern,omitempty" yaml:"pattern,omitempty"` // regex
+	Keyword       string   `json:"keyword,omitempty" yaml:"keyword,omitempty"` // case-insensitive substring
+	Threshold     int      `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	WindowSeconds int      `json:"windowSeconds,omitempty" yaml:"window_seconds,omitempty"`
+	Severity      Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+}
+
+func (r LogRule) Validate() error {
+	if r.Pattern == "" && r.Keyword == "" {
+		return fmt.Errorf("log rule %s needs a pattern or keyword", r.Name)
+	}
+	if r.Pattern != "" {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("log rule %s: invalid pattern: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+// Matcher returns a function reporting whether a line matches the rule.
+func (r LogRule) Matcher() (func(string) bool, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	var re *regexp.Regexp
+	if r.Pattern != "" {
+		re = regexp.MustCompile(r.Pattern)
+	}
+	keyword := strings.ToLower(r.Keyword)
+
+	return func(text string) bool {
+		if re != nil && !re.MatchString(text) {
+			return false
+		}
+		return keyword == "" || strings.Contains(strings.ToLower(text), keyword)
+	}, nil
+}
+
+func (r LogRule) Limit() int {
+	return max(r.Threshold, 1)
+}
+
+func (r LogRule) Window() time.Duration {
+	return secondsOr(r.WindowSeconds, time.Minute)
+}
+
+func (r LogRule) Level() Severity {
+	if r.Severity == "" {
+		return SeverityWarning
+	}
+	return r.Severity
+}
+
+type LogAlert struct {
+	ID         string      `json:"id"`
+	ResourceID string      `json:"resourceId"`
+	Resource   string      `json:"resource"`
+	Rule       string      `json:"rule"`
+	Severity   Severity    `json:"severity"`
+	Count      int         `json:"count"`
+	Window     int64       `json:"window"` // ms
+	Lines      []tail.Line `json:"lines"`
+	At         int64       `json:"at"` // Unix ms
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type SQLConfig struct {
 	ID          string `json:"id" yaml:"id"`
 	Name        string `json:"name" yaml:"name"`
@@ -12,4 +14,40 @@ type Config struct {
 	Version   string           `json:"version" yaml:"version"`
 	Resources []ResourceConfig `json:"resources" yaml:"resources"`
 	SQLConfig *SQLConfig       `json:"sqlConfig,omitempty" yaml:"sql_config,omitempty"`
+	Metrics   *MetricsConfig   `json:"metrics,omitempty" yaml:"metrics,omitempty"`
+
+	Notifications []NotificationChannel `json:"notifications,omitempty" yaml:"notifications,omitempty"`
+}
+
+// MetricsConfig controls the on-disk metrics store kept next to config.yaml.
+// Zero values fall back to the defaults below.
+type MetricsConfig struct {
+	Disabled          bool `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	RetentionDays     int  `json:"retentionDays,omitempty" yaml:"retention_days,omitempty"`
+	CompactAfterHours int  `json:"compactAfterHours,omitempty" yaml:"compact_after_hours,omitempty"`
+}
+
+const (
+	DefaultMetricsRetention    = 7 * 24 * time.Hour
+	DefaultMetricsCompactAfter = 24 * time.Hour
+)
+
+func (m *MetricsConfig) Enabled() bool {
+	return m == nil || !m.Disabled
+}
+
+func (m *MetricsConfig) Retention() time.Duration {
+	if m == nil || m.RetentionDays <= 0 {
+		return DefaultMetricsRetention
+	}
+	return time.Duration(m.RetentionDays) * 24 * time.Hour
+}
+
+// CompactAfter is how old raw samples get before they are merged into
+// per-minute daily segments.
+func (m *MetricsConfig) CompactAfter() time.Duration {
+	if m == nil || m.CompactAfterHours <= 0 {
+		return DefaultMetricsCompactAfter
+	}
+	return time.Duration(m.CompactAfterHours) * time.Hour
 }
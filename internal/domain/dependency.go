@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ValidateDependencies checks that every depends_on entry points to a known
+// resource and that the dependency graph has no cycles.
+func (c *Config) ValidateDependencies() error {
+	byID := c.resourceMap()
+	for _, r := range c.Resources {
+		for _, dep := range r.DependsOn {
+			if dep == r.ID {
+				return fmt.Errorf("resource %s depends on itself", r.Name)
+			}
+			if _, ok := byID[dep]; !ok {
+				return fmt.Errorf("resource %s depends on unknown resource: %s", r.Name, dep)
+			}
+		}
+	}
+
+	all := make([]string, 0, len(c.Resources))
+	for _, r := range c.Resources {
+		all = append(all, r.ID)
+	}
+	_, err := c.order(all, func(r ResourceConfig) []string { return r.DependsOn })
+	return err
+}
+
+// StartOrder returns the given resources plus everything they depend on,
+// sorted so that dependencies come before their dependents.
+func (c *Config) StartOrder(ids []string) ([]ResourceConfig, error) {
+	return c.order(ids, func(r ResourceConfig) []string { return r.DependsOn })
+}
+
+// StopOrder returns the given resources plus everything that depends on them,
+// sorted so that dependents come before the resources they depend on.
+func (c *Config) StopOrder(ids []string) ([]ResourceConfig, error) {
+	dependents := make(map[string][]string)
+	for _, r := range c.Resources {
+		for _, dep := range r.DependsOn {
+			dependents[dep] = append(dependents[dep], r.ID)
+		}
+	}
+	return c.order(ids, func(r ResourceConfig) []string { return dependents[r.ID] })
+}
+
+func (c *Config) resourceMap() map[string]ResourceConfig {
+	byID := make(map[string]ResourceConfig, len(c.Resources))
+	for _, r := range c.Resources {
+		byID[r.ID] = r
+	}
+	return byID
+}
+
+// order walks edges depth first from ids and returns the visited resources in
+// post-order, so every resource appears after all of the resources it points to.
+func (c *Config) order(ids []string, edges func(ResourceConfig) []string) ([]ResourceConfig, error) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	byID := c.resourceMap()
+	state := make(map[string]int, len(byID))
+	result := make([]ResourceConfig, 0, len(ids))
+	var path []string
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		r, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("resource not found for ID: %s", id)
+		}
+
+		switch state[id] {
+		case done:
+			return nil
+		case visiting:
+			var names []string
+			for _, p := range path[slices.Index(path, id):] {
+				names = append(names, byID[p].Name)
+			}
+			names = append(names, r.Name)
+			return fmt.Errorf("dependency cycle detected: %s", strings.Join(names, " -> "))
+		}
+
+		state[id] = visiting
+		path = append(path, id)
+		for _, next := range edges(r) {
+			if err := visit(next); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		result = append(result, r)
+		return nil
+	}
+
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
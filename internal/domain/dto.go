@@ -1,7 +1,35 @@
 package domain
 
+import "zenlight-support/pkg/tail"
+
 type InstallFileDTO struct {
 	Name      string `json:"name"`
 	Data      []byte `json:"data"`
 	Extension string `json:"extension"`
+
+	// For .zip and .tar.gz packages, a leading folder to drop from entry names
+	StripPrefix string `json:"stripPrefix,omitempty"`
+}
+
+type StepResult struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Action   string `json:"action"`
+	Success  bool   `json:"success"`
+	Skipped  bool   `json:"skipped,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Duration int64  `json:"duration"` // ms
+}
+
+type LogStreamOptions struct {
+	Backfill int    `json:"backfill"`
+	Level    string `json:"level,omitempty"`   // minimum level, e.g. WARN
+	Pattern  string `json:"pattern,omitempty"` // regex a line must match
+}
+
+type LogBatch struct {
+	StreamID   string      `json:"streamId"`
+	ResourceID string      `json:"resourceId"`
+	Lines      []tail.Line `json:"lines,omitempty"`
+	Error      string      `json:"error,omitempty"`
 }
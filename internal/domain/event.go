@@ -0,0 +1,28 @@
+package domain
+
+type InstallStage string
+
+const (
+	InstallStarted    InstallStage = "started"
+	InstallCompleted  InstallStage = "completed"
+	InstallFailed     InstallStage = "failed"
+	InstallRolledBack InstallStage = "rolled_back" // previous files restored, followed by failed
+)
+
+type InstallEvent struct {
+	ID    string       `json:"id"`
+	Name  string       `json:"name"`
+	Stage InstallStage `json:"stage"`
+	Files int          `json:"files"`
+	Error string       `json:"error,omitempty"`
+	At    int64        `json:"at"` // Unix ms
+}
+
+// ConfigChange lists the resource IDs affected by a configuration update.
+type ConfigChange struct {
+	Reason  string   `json:"reason"` // save, delete or import
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Updated []string `json:"updated,omitempty"`
+	At      int64    `json:"at"` // Unix ms
+}
@@ -0,0 +1,114 @@
+package domain
+
+// MetricSample is one watcher reading of a resource, flattened for history.
+type MetricSample struct {
+	At          int64   `json:"at"` // Unix ms
+	Status      Status  `json:"status"`
+	CPUUsage    float64 `json:"cpu"`
+	MemUsage    float64 `json:"mem"`
+	TreeCPU     float64 `json:"treeCpu"`
+	TreeMem     float64 `json:"treeMem"`
+	ReadRate    float64 `json:"readRate"`
+	WriteRate   float64 `json:"writeRate"`
+	UsedPercent float64 `json:"usedPercent"`
+	Latency     float64 `json:"latency"` // health check ms
+}
+
+func NewMetricSample(s ResourceStatus, at int64) MetricSample {
+	sample := MetricSample{At: at, Status: s.Status}
+	if m := s.Metrics; m != nil {
+		sample.CPUUsage = m.CPUUsage
+		sample.MemUsage = float64(m.MemUsage)
+		sample.TreeCPU = m.TreeCPUUsage
+		sample.TreeMem = float64(m.TreeMemUsage)
+		sample.ReadRate = m.ReadRate
+		sample.WriteRate = m.WriteRate
+		sample.UsedPercent = m.UsedPercent
+	}
+	if s.Health != nil {
+		sample.Latency = float64(s.Health.Latency)
+	}
+	return sample
+}
+
+type Aggregate struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+	Avg float64 `json:"avg"`
+}
+
+// MetricPoint summarises the samples of one step. Status is the last one seen.
+type MetricPoint struct {
+	At          int64     `json:"at"` // start of the step, Unix ms
+	Samples     int       `json:"samples"`
+	Status      Status    `json:"status"`
+	CPUUsage    Aggregate `json:"cpu"`
+	MemUsage    Aggregate `json:"mem"`
+	TreeCPU     Aggregate `json:"treeCpu"`
+	TreeMem     Aggregate `json:"treeMem"`
+	ReadRate    Aggregate `json:"readRate"`
+	WriteRate   Aggregate `json:"writeRate"`
+	UsedPercent Aggregate `json:"usedPercent"`
+	Latency     Aggregate `json:"latency"`
+}
+
+type MetricHistory struct {
+	ID     string        `json:"id"`
+	From   int64         `json:"from"`
+	To     int64         `json:"to"`
+	Step   int64         `json:"step"` // ms
+	Points []MetricPoint `json:"points"`
+}
+
+// Downsample groups time-ordered samples into steps of step ms starting at
+// from. Steps without samples are left out so charts show a gap.
+func Downsample(samples []MetricSample, from, to, step int64) []MetricPoint {
+	var points []MetricPoint
+	var sums [8]float64
+
+	flush := func() {
+		if n := len(points); n > 0 {
+			p := &points[n-1]
+			for i, a := range p.aggregates() {
+				a.Avg = sums[i] / float64(p.Samples)
+			}
+		}
+		sums = [8]float64{}
+	}
+
+	for _, s := range samples {
+		if s.At < from || s.At >= to {
+			continue
+		}
+		at := from + (s.At-from)/step*step
+		if len(points) == 0 || points[len(points)-1].At != at {
+			flush()
+			points = append(points, MetricPoint{At: at})
+		}
+
+		p := &points[len(points)-1]
+		p.Status = s.Status
+		p.Samples++
+		for i, v := range s.values() {
+			a := p.aggregates()[i]
+			if p.Samples == 1 || v < a.Min {
+				a.Min = v
+			}
+			if p.Samples == 1 || v > a.Max {
+				a.Max = v
+			}
+			sums[i] += v
+		}
+	}
+	flush()
+
+	return points
+}
+
+func (s MetricSample) values() [8]float64 {
+	return [8]float64{s.CPUUsage, s.MemUsage, s.TreeCPU, s.TreeMem, s.ReadRate, s.WriteRate, s.UsedPercent, s.Latency}
+}
+
+func (p *MetricPoint) aggregates() [8]*Aggregate {
+	return [8]*Aggregate{&p.CPUUsage, &p.MemUsage, &p.TreeCPU, &p.TreeMem, &p.ReadRate, &p.WriteRate, &p.UsedPercent, &p.Latency}
+}
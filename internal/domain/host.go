@@ -0,0 +1,50 @@
+package domain
+
+// HostMetrics describes the machine the monitored resources run on.
+type HostMetrics struct {
+	Hostname string `json:"hostname"`
+	OS       string `json:"os"`
+	Platform string `json:"platform"`
+	BootTime int64  `json:"bootTime"` // Unix seconds
+	Uptime   uint64 `json:"uptime"`   // seconds
+
+	CPUCores int     `json:"cpuCores"`
+	CPUUsage float64 `json:"cpu"`
+
+	// Load averages are zero on Windows, which has no equivalent
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+
+	MemTotal    uint64  `json:"memTotal"`
+	MemUsed     uint64  `json:"memUsed"`
+	MemPercent  float64 `json:"memPercent"`
+	SwapTotal   uint64  `json:"swapTotal"`
+	SwapUsed    uint64  `json:"swapUsed"`
+	SwapPercent float64 `json:"swapPercent"`
+
+	Disks    []DiskIO `json:"disks"`
+	Networks []NetIO  `json:"networks"`
+
+	CollectedAt int64 `json:"collectedAt"` // Unix ms
+}
+
+// DiskIO is the throughput of one physical disk since the previous sample.
+type DiskIO struct {
+	Name       string  `json:"name"`
+	ReadRate   float64 `json:"readRate"`  // bytes/s
+	WriteRate  float64 `json:"writeRate"` // bytes/s
+	ReadOps    float64 `json:"readOps"`   // ops/s
+	WriteOps   float64 `json:"writeOps"`  // ops/s
+	ReadBytes  uint64  `json:"readBytes"`
+	WriteBytes uint64  `json:"writeBytes"`
+}
+
+// NetIO is the throughput of one network interface since the previous sample.
+type NetIO struct {
+	Name      string  `json:"name"`
+	RecvRate  float64 `json:"recvRate"` // bytes/s
+	SentRate  float64 `json:"sentRate"` // bytes/s
+	BytesRecv uint64  `json:"bytesRecv"`
+	BytesSent uint64  `json:"bytesSent"`
+}
@@ -0,0 +1,54 @@
+package domain
+
+// Incident is one stretch of downtime of a resource. End is zero while it lasts.
+type Incident struct {
+	ID         string `json:"id"`
+	ResourceID string `json:"resourceId"`
+	Resource   string `json:"resource"`
+	Start      int64  `json:"start"` // Unix ms
+	End        int64  `json:"end,omitempty"`
+	Planned    bool   `json:"planned"`
+	Cause      string `json:"cause"`
+}
+
+// Overlap returns how many ms of the incident fall within [from, to). Open
+// incidents are counted up to now.
+func (i Incident) Overlap(from, to, now int64) int64 {
+	end := i.End
+	if end == 0 {
+		end = now
+	}
+	start := max(i.Start, from)
+	end = min(end, to)
+	return max(end-start, 0)
+}
+
+// Session is one run of the app. End is the last time it was known to be
+// running, so a crash loses at most one heartbeat.
+type Session struct {
+	ID    string `json:"id"`
+	Start int64  `json:"start"` // Unix ms
+	End   int64  `json:"end"`
+}
+
+type AvailabilityReport struct {
+	ResourceID string `json:"resourceId"`
+	Resource   string `json:"resource"`
+	From       int64  `json:"from"`
+	To         int64  `json:"to"`
+
+	// Part of the period the resource was watched, from its first observation
+	// up to now, while the app was running. Unknown is the rest, when the app
+	// was not running and nothing was observed.
+	Monitored         int64 `json:"monitored"`         // ms
+	Unknown           int64 `json:"unknown"`           // ms
+	PlannedDowntime   int64 `json:"plannedDowntime"`   // ms
+	UnplannedDowntime int64 `json:"unplannedDowntime"` // ms
+
+	// Availability leaves planned stops out of the monitored time, Uptime
+	// counts every minute down. Both are zero when nothing was monitored.
+	Availability float64 `json:"availability"` // %
+	Uptime       float64 `json:"uptime"`       // %
+
+	Incidents []Incident `json:"incidents"`
+}
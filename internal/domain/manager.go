@@ -9,9 +9,18 @@ type ResourceManager interface {
 	GetResourceState(resourceName string) (Status, error)
 	GetServiceMetrics(resourceName string) (*ResourceMetrics, error)
 	GetDirectoryMetrics(path string) (*ResourceMetrics, error)
+	GetProcessMetrics(spec ProcessSpec) (*ResourceMetrics, error)
+	GetVolumeMetrics(path string) (*ResourceMetrics, error)
+	ListVolumes() ([]VolumeInfo, error)
+	GetHostMetrics() (*HostMetrics, error)
 
 	StartService(serviceName string) error
 	StopService(serviceName string) error
+	PauseService(serviceName string) error
+	ContinueService(serviceName string) error
+
+	KillProcess(spec ProcessSpec) error
+	LaunchProcess(spec ProcessSpec, dir string) error
 
 	ExecuteSQLScript(server, database, script string) (*sql.Result, error)
 }
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"zenlight-support/pkg/notify"
+)
+
+type ChannelKind string
+
+const (
+	WebhookChannel ChannelKind = "webhook"
+	SMTPChannel    ChannelKind = "smtp"
+)
+
+// NotificationChannel delivers alerts outside the app. Resources limits it to
+// the listed resource IDs or names, empty means all of them.
+type NotificationChannel struct {
+	Name        string          `json:"name" yaml:"name"`
+	Kind        ChannelKind     `json:"kind" yaml:"kind"`
+	Resources   []string        `json:"resources,omitempty" yaml:"resources,omitempty"`
+	MinSeverity Severity        `json:"minSeverity,omitempty" yaml:"min_severity,omitempty"`
+	Webhook     *notify.Webhook `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+	SMTP        *notify.SMTP    `json:"smtp,omitempty" yaml:"smtp,omitempty"`
+}
+
+func (c NotificationChannel) Validate() error {
+	switch c.Kind {
+	case WebhookChannel:
+		if c.Webhook == nil {
+			return fmt.Errorf("notification channel %s needs webhook settings", c.Name)
+		}
+		return c.Webhook.Validate()
+	case SMTPChannel:
+		if c.SMTP == nil {
+			return fmt.Errorf("notification channel %s needs smtp settings", c.Name)
+		}
+		return c.SMTP.Validate()
+	}
+	return fmt.Errorf("notification channel %s has unknown kind %q", c.Name, c.Kind)
+}
+
+func (c NotificationChannel) Sender() notify.Sender {
+	if c.Kind == SMTPChannel {
+		return *c.SMTP
+	}
+	return *c.Webhook
+}
+
+// Routes reports whether n should go out on this channel.
+func (c NotificationChannel) Routes(n Notification) bool {
+	if n.Severity.Rank() < c.MinSeverity.Rank() {
+		return false
+	}
+	return len(c.Resources) == 0 || slices.Contains(c.Resources, n.ResourceID) || slices.Contains(c.Resources, n.Resource)
+}
+
+// Notification is the payload handed to channels and their templates.
+type Notification struct {
+	ID         string   `json:"id"`
+	Source     string   `json:"source"` // alert, log-alert or test
+	ResourceID string   `json:"resourceId"`
+	Resource   string   `json:"resource"`
+	Severity   Severity `json:"severity"`
+	State      string   `json:"state"`
+	Title      string   `json:"title"`
+	Message    string   `json:"message"`
+	At         int64    `json:"at"` // Unix ms
+}
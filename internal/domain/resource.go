@@ -1,11 +1,20 @@
 package domain
 
+import (
+	"fmt"
+	"time"
+	"zenlight-support/pkg/health"
+)
+
 type ResourceType string
 
 const (
 	ServiceType   ResourceType = "service"
 	DirectoryType ResourceType = "directory"
 	SQLScriptType ResourceType = "sqlscript"
+	ProcessType   ResourceType = "process"
+	EndpointType  ResourceType = "endpoint"
+	VolumeType    ResourceType = "volume"
 )
 
 type ResourceConfig struct {
@@ -16,6 +25,147 @@ type ResourceConfig struct {
 	Path        string       `json:"path" yaml:"path"`
 	Installable bool         `json:"installable" yaml:"installable"`
 
+	// Log file paths or glob patterns, the newest match of each is followed
+	Logs     []string  `json:"logs,omitempty" yaml:"logs,omitempty"`
+	LogRules []LogRule `json:"logRules,omitempty" yaml:"log_rules,omitempty"`
+
+	// Threshold rules evaluated on every watcher sample
+	Alerts []AlertRule `json:"alerts,omitempty" yaml:"alerts,omitempty"`
+
+	// IDs of resources that must be up before this one starts
+	DependsOn []string `json:"dependsOn,omitempty" yaml:"depends_on,omitempty"`
+
+	// How often the watcher polls this resource, zero uses the global scan interval
+	PollSeconds int `json:"pollSeconds,omitempty" yaml:"poll_seconds,omitempty"`
+
 	// For services
-	ServiceName string `json:"serviceName,omitempty" yaml:"service_name,omitempty"`
+	ServiceName string         `json:"serviceName,omitempty" yaml:"service_name,omitempty"`
+	KeepRunning *RestartPolicy `json:"keepRunning,omitempty" yaml:"keep_running,omitempty"`
+
+	// For processes
+	Process *ProcessSpec `json:"process,omitempty" yaml:"process,omitempty"`
+
+	// For endpoints, can also be attached to services and directories
+	HealthCheck *health.Probe `json:"healthCheck,omitempty" yaml:"health_check,omitempty"`
+
+	// For volumes, Path is the mount point or drive root
+	Volume *VolumeThresholds `json:"volume,omitempty" yaml:"volume,omitempty"`
+}
+
+// Validate checks the settings a resource needs for its type and its rules.
+// Dependencies are checked across the whole config by ValidateDependencies.
+func (r ResourceConfig) Validate() error {
+	if r.Type == ProcessType && (r.Process == nil || r.Process.IsEmpty()) {
+		return fmt.Errorf("process resource needs a name, executable or command pattern")
+	}
+	if r.Type == EndpointType && r.HealthCheck == nil {
+		return fmt.Errorf("endpoint resource needs a health check")
+	}
+	for _, rule := range r.LogRules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := ValidateAlertRules(r.Alerts); err != nil {
+		return err
+	}
+	if r.HealthCheck != nil {
+		if err := r.HealthCheck.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r ResourceConfig) PollInterval(fallback time.Duration) time.Duration {
+	return secondsOr(r.PollSeconds, fallback)
+}
+
+// VolumeThresholds are used space percentages. Zero values fall back to the defaults.
+type VolumeThresholds struct {
+	WarningPercent  float64 `json:"warningPercent,omitempty" yaml:"warning_percent,omitempty"`
+	CriticalPercent float64 `json:"criticalPercent,omitempty" yaml:"critical_percent,omitempty"`
+}
+
+const (
+	DefaultVolumeWarning  = 85.0
+	DefaultVolumeCritical = 95.0
+)
+
+// Evaluate returns the severity for the given used space percentage, or "" when healthy.
+func (v *VolumeThresholds) Evaluate(usedPercent float64) Severity {
+	warning, critical := DefaultVolumeWarning, DefaultVolumeCritical
+	if v != nil {
+		if v.WarningPercent > 0 {
+			warning = v.WarningPercent
+		}
+		if v.CriticalPercent > 0 {
+			critical = v.CriticalPercent
+		}
+	}
+
+	switch {
+	case usedPercent >= critical:
+		return SeverityCritical
+	case usedPercent >= warning:
+		return SeverityWarning
+	}
+	return ""
+}
+
+// ProcessSpec matches plain executables that do not run as services. Every
+// criterion that is set must match; at least one is required.
+type ProcessSpec struct {
+	Name           string   `json:"name,omitempty" yaml:"name,omitempty"`                      // executable name, e.g. PosAgent.exe
+	Executable     string   `json:"executable,omitempty" yaml:"executable,omitempty"`          // full executable path, also used to launch
+	CommandPattern string   `json:"commandPattern,omitempty" yaml:"command_pattern,omitempty"` // regex on the command line
+	LaunchArgs     []string `json:"launchArgs,omitempty" yaml:"launch_args,omitempty"`
+	AllowKill      bool     `json:"allowKill,omitempty" yaml:"allow_kill,omitempty"`
+}
+
+func (p ProcessSpec) IsEmpty() bool {
+	return p.Name == "" && p.Executable == "" && p.CommandPattern == ""
+}
+
+// RestartPolicy tells the watcher to bring a service back up when it stops
+// on its own. Zero values fall back to the defaults below.
+type RestartPolicy struct {
+	Enabled           bool `json:"enabled" yaml:"enabled"`
+	BackoffSeconds    int  `json:"backoffSeconds,omitempty" yaml:"backoff_seconds,omitempty"`
+	MaxBackoffSeconds int  `json:"maxBackoffSeconds,omitempty" yaml:"max_backoff_seconds,omitempty"`
+	MaxRestarts       int  `json:"maxRestarts,omitempty" yaml:"max_restarts,omitempty"`
+	WindowSeconds     int  `json:"windowSeconds,omitempty" yaml:"window_seconds,omitempty"`
+}
+
+const (
+	DefaultBackoff     = 5 * time.Second
+	DefaultMaxBackoff  = 5 * time.Minute
+	DefaultMaxRestarts = 5
+	DefaultWindow      = 10 * time.Minute
+)
+
+func (p RestartPolicy) Backoff() time.Duration {
+	return secondsOr(p.BackoffSeconds, DefaultBackoff)
+}
+
+func (p RestartPolicy) MaxBackoff() time.Duration {
+	return secondsOr(p.MaxBackoffSeconds, DefaultMaxBackoff)
+}
+
+func (p RestartPolicy) Limit() int {
+	if p.MaxRestarts > 0 {
+		return p.MaxRestarts
+	}
+	return DefaultMaxRestarts
+}
+
+func (p RestartPolicy) Window() time.Duration {
+	return secondsOr(p.WindowSeconds, DefaultWindow)
+}
+
+func secondsOr(seconds int, fallback time.Duration) time.Duration {
+	if seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
 }
@@ -1,16 +1,95 @@
 package domain
 
+import "zenlight-support/pkg/health"
+
+// Status mirrors the Windows SCM service states so native values can be used as is.
 type Status int8
 
 const (
-	STOPPED Status = 1 // Running
-	RUNNING Status = 4 // Stopped
+	UNKNOWN          Status = 0
+	STOPPED          Status = 1
+	START_PENDING    Status = 2
+	STOP_PENDING     Status = 3
+	RUNNING          Status = 4
+	CONTINUE_PENDING Status = 5
+	PAUSE_PENDING    Status = 6
+	PAUSED           Status = 7
 )
 
+var statusNames = map[Status]string{
+	UNKNOWN:          "UNKNOWN",
+	STOPPED:          "STOPPED",
+	START_PENDING:    "START_PENDING",
+	STOP_PENDING:     "STOP_PENDING",
+	RUNNING:          "RUNNING",
+	CONTINUE_PENDING: "CONTINUE_PENDING",
+	PAUSE_PENDING:    "PAUSE_PENDING",
+	PAUSED:           "PAUSED",
+}
+
+// transitions lists the states a service may move to from each state.
+var transitions = map[Status][]Status{
+	STOPPED:          {START_PENDING, RUNNING},
+	START_PENDING:    {RUNNING, STOPPED, STOP_PENDING},
+	RUNNING:          {STOP_PENDING, STOPPED, PAUSE_PENDING, PAUSED},
+	STOP_PENDING:     {STOPPED, RUNNING},
+	PAUSE_PENDING:    {PAUSED, RUNNING, STOP_PENDING, STOPPED},
+	PAUSED:           {CONTINUE_PENDING, RUNNING, STOP_PENDING, STOPPED},
+	CONTINUE_PENDING: {RUNNING, PAUSED, STOP_PENDING, STOPPED},
+}
+
+func (s Status) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return statusNames[UNKNOWN]
+}
+
+func (s Status) IsValid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// IsPending reports whether the service is between two stable states.
+func (s Status) IsPending() bool {
+	switch s {
+	case START_PENDING, STOP_PENDING, CONTINUE_PENDING, PAUSE_PENDING:
+		return true
+	}
+	return false
+}
+
+// IsActive reports whether the service has a live process.
+func (s Status) IsActive() bool {
+	return s != STOPPED && s != UNKNOWN
+}
+
+// CanTransitionTo reports whether moving from s to next is a legal transition.
+// UNKNOWN may move anywhere since the previous state was never observed.
+func (s Status) CanTransitionTo(next Status) bool {
+	if s == next || s == UNKNOWN {
+		return true
+	}
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 type ResourceStatus struct {
-	ID      string           `json:"id"`
-	Status  Status           `json:"status"`
-	Metrics *ResourceMetrics `json:"metrics,omitempty"`
+	ID       string           `json:"id"`
+	Status   Status           `json:"status"`
+	Since    int64            `json:"since,omitempty"`   // Unix ms when the current status was entered
+	Elapsed  int64            `json:"elapsed,omitempty"` // ms spent in the current status
+	Metrics  *ResourceMetrics `json:"metrics,omitempty"`
+	Health   *health.Result   `json:"health,omitempty"`
+	Severity Severity         `json:"severity,omitempty"` // threshold verdict for volumes
+
+	Error     string `json:"error,omitempty"`     // why the last probe failed
+	CheckedAt int64  `json:"checkedAt,omitempty"` // Unix ms of the last probe
+	ChangedAt int64  `json:"changedAt,omitempty"` // Unix ms of the last reported change
 }
 
 type ResourceMetrics struct {
@@ -20,7 +99,61 @@ type ResourceMetrics struct {
 	CPUUsage   float64 `json:"cpu"`
 	MemUsage   uint64  `json:"mem"`
 
+	// --- Process ---
+	PIDs        []uint32 `json:"pids,omitempty"`
+	Threads     int32    `json:"threads,omitempty"`
+	Handles     int32    `json:"handles,omitempty"`   // open handles on Windows, file descriptors elsewhere
+	ReadRate    float64  `json:"readRate,omitempty"`  // bytes/s
+	WriteRate   float64  `json:"writeRate,omitempty"` // bytes/s
+	ListenPorts []uint32 `json:"listenPorts,omitempty"`
+
+	// --- Process tree (main process plus all descendants) ---
+	TreeCPUUsage float64          `json:"treeCpu,omitempty"`
+	TreeMemUsage uint64           `json:"treeMem,omitempty"`
+	ChildCount   int              `json:"childCount,omitempty"`
+	Children     []ProcessMetrics `json:"children,omitempty"`
+
 	// --- Directory ---
 	TotalSize    int64 `json:"totalSize,omitempty"`
+	FileCount    int64 `json:"fileCount,omitempty"`
 	LastModified int64 `json:"lastModified,omitempty"`
+
+	// --- Volume ---
+	VolumeTotal uint64  `json:"volumeTotal,omitempty"`
+	VolumeUsed  uint64  `json:"volumeUsed,omitempty"`
+	VolumeFree  uint64  `json:"volumeFree,omitempty"`
+	UsedPercent float64 `json:"usedPercent,omitempty"`
+	GrowthRate  float64 `json:"growthRate,omitempty"` // bytes/hour of used space, negative when shrinking
+}
+
+type VolumeInfo struct {
+	Device      string  `json:"device"`
+	Mountpoint  string  `json:"mountpoint"`
+	Fstype      string  `json:"fstype"`
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Free        uint64  `json:"free"`
+	UsedPercent float64 `json:"usedPercent"`
+}
+
+// ProcessMetrics is the breakdown for a single process inside a tree.
+type ProcessMetrics struct {
+	PID        uint32  `json:"pid"`
+	ParentPID  uint32  `json:"parentPid"`
+	Name       string  `json:"name"`
+	CreateTime int64   `json:"createTime"`
+	CPUUsage   float64 `json:"cpu"`
+	MemUsage   uint64  `json:"mem"`
+}
+
+// RestartAttempt records one automatic restart made by the watcher.
+type RestartAttempt struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	At        int64  `json:"at"`      // Unix ms
+	Attempt   int    `json:"attempt"` // restarts within the current window
+	Backoff   int64  `json:"backoff"` // ms until the next attempt is allowed
+	Success   bool   `json:"success"`
+	CrashLoop bool   `json:"crashLoop,omitempty"`
+	Error     string `json:"error,omitempty"`
 }
@@ -0,0 +1,238 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AlertRule raises an alert when Expr holds for its duration. Expr has the
+// form "<metric> <op> <value> [for <duration>]", for example
+//
+//	cpu > 80% for 5m
+//	mem > 1.5GB
+//	status == stopped for 60s
+//	dir_size > 10GB
+type AlertRule struct {
+	Name     string   `json:"name" yaml:"name"`
+	Expr     string   `json:"expr" yaml:"expr"`
+	Severity Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+}
+
+// Validate checks the expression. Rules need a name since open alerts are
+// tracked by resource and rule name.
+func (r AlertRule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("alert rule %q needs a name", r.Expr)
+	}
+	_, err := r.Condition()
+	return err
+}
+
+// ValidateAlertRules checks every rule and that no two share a name.
+func ValidateAlertRules(rules []AlertRule) error {
+	seen := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		if seen[r.Name] {
+			return fmt.Errorf("duplicate alert rule name: %s", r.Name)
+		}
+		seen[r.Name] = true
+	}
+	return nil
+}
+
+func (r AlertRule) Level() Severity {
+	if r.Severity == "" {
+		return SeverityWarning
+	}
+	return r.Severity
+}
+
+// Condition is a parsed AlertRule expression.
+type Condition struct {
+	Metric    string
+	Op        string
+	Threshold float64
+	Status    Status // for the status metric
+	For       time.Duration
+}
+
+var alertMetrics = map[string]func(ResourceStatus) (float64, bool){
+	"cpu":       metric(func(m *ResourceMetrics) float64 { return m.CPUUsage }),
+	"mem":       metric(func(m *ResourceMetrics) float64 { return float64(m.MemUsage) }),
+	"tree_cpu":  metric(func(m *ResourceMetrics) float64 { return m.TreeCPUUsage }),
+	"tree_mem":  metric(func(m *ResourceMetrics) float64 { return float64(m.TreeMemUsage) }),
+	"threads":   metric(func(m *ResourceMetrics) float64 { return float64(m.Threads) }),
+	"handles":   metric(func(m *ResourceMetrics) float64 { return float64(m.Handles) }),
+	"dir_size":  metric(func(m *ResourceMetrics) float64 { return float64(m.TotalSize) }),
+	"files":     metric(func(m *ResourceMetrics) float64 { return float64(m.FileCount) }),
+	"disk":      metric(func(m *ResourceMetrics) float64 { return m.UsedPercent }),
+	"disk_free": metric(func(m *ResourceMetrics) float64 { return float64(m.VolumeFree) }),
+	"latency": func(s ResourceStatus) (float64, bool) {
+		if s.Health == nil {
+			return 0, false
+		}
+		return float64(s.Health.Latency), true
+	},
+}
+
+func metric(get func(*ResourceMetrics) float64) func(ResourceStatus) (float64, bool) {
+	return func(s ResourceStatus) (float64, bool) {
+		if s.Metrics == nil {
+			return 0, false
+		}
+		return get(s.Metrics), true
+	}
+}
+
+func (r AlertRule) Condition() (Condition, error) {
+	fields := strings.Fields(strings.ToLower(r.Expr))
+
+	var c Condition
+	if n := len(fields); n == 5 && fields[3] == "for" {
+		d, err := time.ParseDuration(fields[4])
+		if err != nil {
+			return c, fmt.Errorf("alert rule %s: invalid duration %q", r.Name, fields[4])
+		}
+		c.For = d
+		fields = fields[:3]
+	}
+	if len(fields) != 3 {
+		return c, fmt.Errorf("alert rule %s: expected \"<metric> <op> <value> [for <duration>]\", got %q", r.Name, r.Expr)
+	}
+
+	c.Metric, c.Op = fields[0], fields[1]
+	switch c.Op {
+	case ">", ">=", "<", "<=", "==", "!=":
+	default:
+		return c, fmt.Errorf("alert rule %s: unknown operator %q", r.Name, c.Op)
+	}
+
+	if c.Metric == "status" {
+		if c.Op != "==" && c.Op != "!=" {
+			return c, fmt.Errorf("alert rule %s: status only supports == and !=", r.Name)
+		}
+		status, ok := parseStatus(fields[2])
+		if !ok {
+			return c, fmt.Errorf("alert rule %s: unknown status %q", r.Name, fields[2])
+		}
+		c.Status = status
+		return c, nil
+	}
+
+	if _, ok := alertMetrics[c.Metric]; !ok {
+		return c, fmt.Errorf("alert rule %s: unknown metric %q", r.Name, c.Metric)
+	}
+	v, err := parseQuantity(fields[2])
+	if err != nil {
+		return c, fmt.Errorf("alert rule %s: %w", r.Name, err)
+	}
+	c.Threshold = v
+	return c, nil
+}
+
+// Eval returns the observed value and whether the condition holds. Metrics
+// that were not measured never match.
+func (c Condition) Eval(s ResourceStatus) (float64, bool) {
+	if c.Metric == "status" {
+		match := s.Status == c.Status
+		if c.Op == "!=" {
+			match = !match
+		}
+		return float64(s.Status), match
+	}
+
+	v, ok := alertMetrics[c.Metric](s)
+	if !ok {
+		return 0, false
+	}
+	switch c.Op {
+	case ">":
+		return v, v > c.Threshold
+	case ">=":
+		return v, v >= c.Threshold
+	case "<":
+		return v, v < c.Threshold
+	case "<=":
+		return v, v <= c.Threshold
+	case "==":
+		return v, v == c.Threshold
+	case "!=":
+		return v, v != c.Threshold
+	}
+	return v, false
+}
+
+// Measured reports whether s carries the value the condition looks at. The
+// status is always known, metrics are missing when a probe failed.
+func (c Condition) Measured(s ResourceStatus) bool {
+	if c.Metric == "status" {
+		return true
+	}
+	_, ok := alertMetrics[c.Metric](s)
+	return ok
+}
+
+func parseStatus(name string) (Status, bool) {
+	for s, n := range statusNames {
+		if strings.EqualFold(n, name) {
+			return s, true
+		}
+	}
+	return UNKNOWN, false
+}
+
+var sizeUnits = []struct {
+	suffix string
+	scale  float64
+}{
+	{"tb", 1 << 40}, {"gb", 1 << 30}, {"mb", 1 << 20}, {"kb", 1 << 10}, {"b", 1},
+}
+
+// parseQuantity reads a number with an optional % or byte size suffix.
+func parseQuantity(s string) (float64, error) {
+	scale := 1.0
+	num := strings.TrimSuffix(s, "%")
+	if num == s {
+		for _, u := range sizeUnits {
+			if strings.HasSuffix(s, u.suffix) {
+				num, scale = strings.TrimSuffix(s, u.suffix), u.scale
+				break
+			}
+		}
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return v * scale, nil
+}
+
+type AlertState string
+
+const (
+	AlertPending      AlertState = "pending"
+	AlertFiring       AlertState = "firing"
+	AlertAcknowledged AlertState = "acknowledged"
+	AlertResolved     AlertState = "resolved"
+)
+
+type Alert struct {
+	ID            string     `json:"id"`
+	ResourceID    string     `json:"resourceId"`
+	Resource      string     `json:"resource"`
+	Rule          string     `json:"rule"`
+	Expr          string     `json:"expr"`
+	Severity      Severity   `json:"severity"`
+	State         AlertState `json:"state"`
+	Value         float64    `json:"value"` // last observed value
+	StartedAt     int64      `json:"startedAt"`
+	FiredAt       int64      `json:"firedAt,omitempty"`
+	AckedAt       int64      `json:"ackedAt,omitempty"`
+	ResolvedAt    int64      `json:"resolvedAt,omitempty"`
+	SilencedUntil int64      `json:"silencedUntil,omitempty"` // Unix ms, notifications are muted until then
+}
@@ -0,0 +1,48 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"zenlight-support/internal/domain"
+)
+
+func directoryMetrics(path string) (*domain.ResourceMetrics, error) {
+	cleanPath := filepath.Clean(os.ExpandEnv(path))
+
+	info, err := os.Stat(cleanPath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path is not a directory: %s", cleanPath)
+	}
+
+	var totalSize int64
+	var fileCount int64
+	var lastModified int64
+
+	err = filepath.Walk(cleanPath, func(_ string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			totalSize += fi.Size()
+			fileCount++
+			modTime := fi.ModTime().UnixNano() / 1e6
+			if modTime > lastModified {
+				lastModified = modTime
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ResourceMetrics{
+		TotalSize:    totalSize,
+		FileCount:    fileCount,
+		LastModified: lastModified,
+	}, nil
+}
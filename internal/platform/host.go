@@ -0,0 +1,101 @@
+package platform
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+	"zenlight-support/internal/domain"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/load"
+	"github.com/shirou/gopsutil/v4/mem"
+	"github.com/shirou/gopsutil/v4/net"
+)
+
+// hostCollector samples machine-wide metrics. Disk and network rates are
+// computed against the previous sample and stay zero on the first call.
+type hostCollector struct {
+	mu       sync.Mutex
+	lastAt   time.Time
+	lastDisk map[string]disk.IOCountersStat
+	lastNet  map[string]net.IOCountersStat
+}
+
+func newHostCollector() *hostCollector {
+	return &hostCollector{}
+}
+
+func (c *hostCollector) metrics() (*domain.HostMetrics, error) {
+	info, err := host.Info()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	metrics := &domain.HostMetrics{
+		Hostname:    info.Hostname,
+		OS:          info.OS,
+		Platform:    info.Platform,
+		BootTime:    int64(info.BootTime),
+		Uptime:      info.Uptime,
+		CollectedAt: now.UnixMilli(),
+	}
+
+	if cores, err := cpu.Counts(true); err == nil {
+		metrics.CPUCores = cores
+	}
+	// A zero interval measures against the previous call
+	if usage, err := cpu.Percent(0, false); err == nil && len(usage) > 0 {
+		metrics.CPUUsage = usage[0]
+	}
+	if avg, err := load.Avg(); err == nil {
+		metrics.Load1, metrics.Load5, metrics.Load15 = avg.Load1, avg.Load5, avg.Load15
+	}
+	if vm, err := mem.VirtualMemory(); err == nil {
+		metrics.MemTotal, metrics.MemUsed, metrics.MemPercent = vm.Total, vm.Used, vm.UsedPercent
+	}
+	if swap, err := mem.SwapMemory(); err == nil {
+		metrics.SwapTotal, metrics.SwapUsed, metrics.SwapPercent = swap.Total, swap.Used, swap.UsedPercent
+	}
+
+	disks, _ := disk.IOCounters()
+	nets, _ := net.IOCounters(true)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elapsed := 0.0
+	if !c.lastAt.IsZero() {
+		elapsed = now.Sub(c.lastAt).Seconds()
+	}
+
+	for name, d := range disks {
+		io := domain.DiskIO{Name: name, ReadBytes: d.ReadBytes, WriteBytes: d.WriteBytes}
+		if prev, ok := c.lastDisk[name]; ok && elapsed > 0 {
+			io.ReadRate = rate(prev.ReadBytes, d.ReadBytes, elapsed)
+			io.WriteRate = rate(prev.WriteBytes, d.WriteBytes, elapsed)
+			io.ReadOps = rate(prev.ReadCount, d.ReadCount, elapsed)
+			io.WriteOps = rate(prev.WriteCount, d.WriteCount, elapsed)
+		}
+		metrics.Disks = append(metrics.Disks, io)
+	}
+	slices.SortFunc(metrics.Disks, func(a, b domain.DiskIO) int { return cmp.Compare(a.Name, b.Name) })
+
+	lastNet := make(map[string]net.IOCountersStat, len(nets))
+	for _, n := range nets {
+		io := domain.NetIO{Name: n.Name, BytesRecv: n.BytesRecv, BytesSent: n.BytesSent}
+		if prev, ok := c.lastNet[n.Name]; ok && elapsed > 0 {
+			io.RecvRate = rate(prev.BytesRecv, n.BytesRecv, elapsed)
+			io.SentRate = rate(prev.BytesSent, n.BytesSent, elapsed)
+		}
+		metrics.Networks = append(metrics.Networks, io)
+		lastNet[n.Name] = n
+	}
+	slices.SortFunc(metrics.Networks, func(a, b domain.NetIO) int { return cmp.Compare(a.Name, b.Name) })
+
+	c.lastAt, c.lastDisk, c.lastNet = now, disks, lastNet
+	return metrics, nil
+}
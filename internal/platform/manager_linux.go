@@ -0,0 +1,341 @@
+//go:build linux
+
+package platform
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"zenlight-support/internal/domain"
+	"zenlight-support/pkg/sql"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+const systemctlTimeout = 10 * time.Second
+
+// CommandRunner runs an external command and returns its standard output.
+// It lets tests replace systemctl with canned output.
+type CommandRunner interface {
+	Run(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+type execRunner struct{}
+
+func (execRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return out, fmt.Errorf("%s: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return out, err
+	}
+	return out, nil
+}
+
+type unitStatus struct {
+	ActiveState  string
+	SubState     string
+	LoadState    string
+	FreezerState string
+	MainPID      int32
+}
+
+type LinuxManager struct {
+	runner       CommandRunner
+	mu           sync.RWMutex
+	connected    bool
+	processCache map[string]*processHandle
+	procs        *processTracker
+	volumes      *volumeTracker
+	host         *hostCollector
+}
+
+// ExecuteSQLScript implements [domain.ResourceManager].
+func (l *LinuxManager) ExecuteSQLScript(server string, database string, script string) (*sql.Result, error) {
+	executor := sql.NewExecutor(server, database)
+	return executor.Execute(context.Background(), script)
+}
+
+// GetProcessMetrics implements [domain.ResourceManager].
+func (l *LinuxManager) GetProcessMetrics(spec domain.ProcessSpec) (*domain.ResourceMetrics, error) {
+	return l.procs.metrics(spec)
+}
+
+// GetVolumeMetrics implements [domain.ResourceManager].
+func (l *LinuxManager) GetVolumeMetrics(path string) (*domain.ResourceMetrics, error) {
+	return l.volumes.metrics(path)
+}
+
+// GetHostMetrics implements [domain.ResourceManager].
+func (l *LinuxManager) GetHostMetrics() (*domain.HostMetrics, error) {
+	return l.host.metrics()
+}
+
+// ListVolumes implements [domain.ResourceManager].
+func (l *LinuxManager) ListVolumes() ([]domain.VolumeInfo, error) {
+	return listVolumes()
+}
+
+// KillProcess implements [domain.ResourceManager].
+func (l *LinuxManager) KillProcess(spec domain.ProcessSpec) error {
+	return l.procs.kill(spec)
+}
+
+// LaunchProcess implements [domain.ResourceManager].
+func (l *LinuxManager) LaunchProcess(spec domain.ProcessSpec, dir string) error {
+	return launchProcess(spec, dir)
+}
+
+// GetDirectoryMetrics implements [domain.ResourceManager].
+func (l *LinuxManager) GetDirectoryMetrics(path string) (*domain.ResourceMetrics, error) {
+	return directoryMetrics(path)
+}
+
+// Connect implements [domain.ResourceManager].
+func (l *LinuxManager) Connect() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.connected {
+		return nil
+	}
+	if _, err := l.systemctl("--version"); err != nil {
+		return fmt.Errorf("failed to connect to systemd: %w", err)
+	}
+	l.connected = true
+	return nil
+}
+
+// Disconnect implements [domain.ResourceManager].
+func (l *LinuxManager) Disconnect() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.connected = false
+	l.processCache = make(map[string]*processHandle)
+	return nil
+}
+
+// GetServiceMetrics implements [domain.ResourceManager].
+func (l *LinuxManager) GetServiceMetrics(serviceName string) (*domain.ResourceMetrics, error) {
+	unit, err := l.queryUnit(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if unit.MainPID == 0 {
+		l.mu.Lock()
+		delete(l.processCache, serviceName)
+		l.mu.Unlock()
+		return nil, nil
+	}
+
+	l.mu.Lock()
+	handle, exists := l.processCache[serviceName]
+
+	if !exists || handle.lastPID != unit.MainPID {
+		p, err := process.NewProcess(unit.MainPID)
+		if err != nil {
+			l.mu.Unlock()
+			return nil, err
+		}
+		handle = newProcessHandle(p)
+		l.processCache[serviceName] = handle
+	}
+	l.mu.Unlock()
+
+	return handle.collect(), nil
+}
+
+// GetResourceState implements [domain.ResourceManager].
+func (l *LinuxManager) GetResourceState(serviceName string) (domain.Status, error) {
+	unit, err := l.queryUnit(serviceName)
+	if err != nil {
+		return domain.STOPPED, err
+	}
+	return unit.status(), nil
+}
+
+// StartService implements [domain.ResourceManager].
+func (l *LinuxManager) StartService(serviceName string) error {
+	if err := l.ensureConnected(); err != nil {
+		return err
+	}
+	_, err := l.systemctl("start", "--no-block", unitName(serviceName))
+	return err
+}
+
+// StopService implements [domain.ResourceManager].
+func (l *LinuxManager) StopService(serviceName string) error {
+	if err := l.ensureConnected(); err != nil {
+		return err
+	}
+	_, err := l.systemctl("stop", "--no-block", unitName(serviceName))
+	return err
+}
+
+// PauseService implements [domain.ResourceManager].
+func (l *LinuxManager) PauseService(serviceName string) error {
+	if err := l.ensureConnected(); err != nil {
+		return err
+	}
+	if err := l.guard(serviceName, "pause", domain.PAUSE_PENDING); err != nil {
+		return err
+	}
+	_, err := l.systemctl("freeze", unitName(serviceName))
+	return err
+}
+
+// ContinueService implements [domain.ResourceManager].
+func (l *LinuxManager) ContinueService(serviceName string) error {
+	if err := l.ensureConnected(); err != nil {
+		return err
+	}
+	if err := l.guard(serviceName, "continue", domain.CONTINUE_PENDING); err != nil {
+		return err
+	}
+	_, err := l.systemctl("thaw", unitName(serviceName))
+	return err
+}
+
+func (l *LinuxManager) ensureConnected() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if !l.connected {
+		return fmt.Errorf("not connected")
+	}
+	return nil
+}
+
+func (l *LinuxManager) queryUnit(serviceName string) (*unitStatus, error) {
+	if err := l.ensureConnected(); err != nil {
+		return nil, err
+	}
+
+	out, err := l.systemctl("show", unitName(serviceName),
+		"--property=LoadState,ActiveState,SubState,FreezerState,MainPID")
+	if err != nil {
+		return nil, err
+	}
+
+	unit, err := parseUnitStatus(out)
+	if err != nil {
+		return nil, err
+	}
+	if unit.LoadState == "not-found" {
+		return nil, fmt.Errorf("service not found: %s", serviceName)
+	}
+	return unit, nil
+}
+
+// guard rejects an operation whose pending state the unit cannot move to from
+// its current one, such as pausing a stopped unit, with the same error the
+// simulator gives instead of whatever systemd makes of it.
+func (l *LinuxManager) guard(serviceName, op string, pending domain.Status) error {
+	unit, err := l.queryUnit(serviceName)
+	if err != nil {
+		return err
+	}
+	if current := unit.status(); !current.CanTransitionTo(pending) {
+		return fmt.Errorf("cannot %s service %s while %s", op, serviceName, current)
+	}
+	return nil
+}
+
+func (l *LinuxManager) systemctl(args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), systemctlTimeout)
+	defer cancel()
+	return l.runner.Run(ctx, "systemctl", args...)
+}
+
+// status maps systemd unit states onto the SCM style state machine. Paused
+// services are units frozen through the cgroup freezer.
+func (u *unitStatus) status() domain.Status {
+	switch u.ActiveState {
+	case "activating":
+		return domain.START_PENDING
+	case "deactivating":
+		return domain.STOP_PENDING
+	case "active", "reloading":
+		switch u.FreezerState {
+		case "freezing":
+			return domain.PAUSE_PENDING
+		case "frozen":
+			return domain.PAUSED
+		case "thawing":
+			return domain.CONTINUE_PENDING
+		}
+		return domain.RUNNING
+	case "inactive", "failed":
+		return domain.STOPPED
+	default:
+		return domain.UNKNOWN
+	}
+}
+
+func parseUnitStatus(out []byte) (*unitStatus, error) {
+	unit := &unitStatus{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "LoadState":
+			unit.LoadState = value
+		case "ActiveState":
+			unit.ActiveState = value
+		case "SubState":
+			unit.SubState = value
+		case "FreezerState":
+			unit.FreezerState = value
+		case "MainPID":
+			pid, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid MainPID %q: %w", value, err)
+			}
+			unit.MainPID = int32(pid)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if unit.ActiveState == "" {
+		return nil, fmt.Errorf("unexpected systemctl output")
+	}
+	return unit, nil
+}
+
+// unitName appends the .service suffix when the configured name has no unit type.
+func unitName(serviceName string) string {
+	if strings.Contains(serviceName, ".") {
+		return serviceName
+	}
+	return serviceName + ".service"
+}
+
+// NewLinuxManager creates a systemd backed manager that shells out through runner.
+func NewLinuxManager(runner CommandRunner) *LinuxManager {
+	return &LinuxManager{
+		runner:       runner,
+		processCache: make(map[string]*processHandle),
+		procs:        newProcessTracker(),
+		volumes:      newVolumeTracker(),
+		host:         newHostCollector(),
+	}
+}
+
+func NewManager() domain.ResourceManager {
+	if m := newManagerFromEnv(); m != nil {
+		return m
+	}
+	return NewLinuxManager(execRunner{})
+}
@@ -0,0 +1,222 @@
+//go:build linux
+
+package platform
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"zenlight-support/internal/domain"
+)
+
+// fakeRunner answers systemctl calls by their verb and records every call.
+type fakeRunner struct {
+	out   map[string]string
+	errs  map[string]error
+	calls []string
+}
+
+func (f *fakeRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, name+" "+strings.Join(args, " "))
+	return []byte(f.out[args[0]]), f.errs[args[0]]
+}
+
+func connected(t *testing.T, runner *fakeRunner) *LinuxManager {
+	t.Helper()
+	l := NewLinuxManager(runner)
+	if err := l.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func show(active, sub, freezer string, pid int) string {
+	return "LoadState=loaded\nActiveState=" + active + "\nSubState=" + sub + "\nFreezerState=" + freezer + "\nMainPID=" + strconv.Itoa(pid) + "\n"
+}
+
+func TestGetResourceState(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    domain.Status
+		wantPID int32
+		wantErr string
+	}{
+		{name: "running", out: show("active", "running", "running", 1234), want: domain.RUNNING, wantPID: 1234},
+		{name: "reloading", out: show("reloading", "reload", "running", 1234), want: domain.RUNNING, wantPID: 1234},
+		{name: "activating", out: show("activating", "start-pre", "running", 0), want: domain.START_PENDING},
+		{name: "deactivating", out: show("deactivating", "stop-sigterm", "running", 1234), want: domain.STOP_PENDING, wantPID: 1234},
+		{name: "inactive", out: show("inactive", "dead", "running", 0), want: domain.STOPPED},
+		{name: "failed", out: show("failed", "failed", "running", 0), want: domain.STOPPED},
+		{name: "freezing", out: show("active", "running", "freezing", 1234), want: domain.PAUSE_PENDING, wantPID: 1234},
+		{name: "frozen", out: show("active", "running", "frozen", 1234), want: domain.PAUSED, wantPID: 1234},
+		{name: "thawing", out: show("active", "running", "thawing", 1234), want: domain.CONTINUE_PENDING, wantPID: 1234},
+		{name: "unknown active state", out: show("maintenance", "", "", 0), want: domain.UNKNOWN},
+		{name: "older systemd without freezer", out: "ActiveState=active\nMainPID=42\n", want: domain.RUNNING, wantPID: 42},
+		{
+			name:    "not found",
+			out:     "LoadState=not-found\nActiveState=inactive\nSubState=dead\nFreezerState=running\nMainPID=0\n",
+			wantErr: "service not found: app",
+		},
+		{name: "no active state", out: "LoadState=loaded\nMainPID=0\n", wantErr: "unexpected systemctl output"},
+		{name: "invalid pid", out: "ActiveState=active\nMainPID=abc\n", wantErr: "invalid MainPID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeRunner{out: map[string]string{"show": tt.out}}
+			l := connected(t, runner)
+
+			got, err := l.GetResourceState("app")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GetResourceState() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetResourceState() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetResourceState() = %s, want %s", got, tt.want)
+			}
+
+			unit, err := parseUnitStatus([]byte(tt.out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if unit.MainPID != tt.wantPID {
+				t.Errorf("MainPID = %d, want %d", unit.MainPID, tt.wantPID)
+			}
+		})
+	}
+}
+
+func TestQueryUnitCommand(t *testing.T) {
+	runner := &fakeRunner{out: map[string]string{"show": show("active", "running", "running", 1)}}
+	l := connected(t, runner)
+	if _, err := l.GetResourceState("nginx"); err != nil {
+		t.Fatal(err)
+	}
+	want := "systemctl show nginx.service --property=LoadState,ActiveState,SubState,FreezerState,MainPID"
+	if got := runner.calls[len(runner.calls)-1]; got != want {
+		t.Errorf("ran %q, want %q", got, want)
+	}
+}
+
+func TestUnitName(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"nginx", "nginx.service"},
+		{"nginx.service", "nginx.service"},
+		{"backup.timer", "backup.timer"},
+		{"app@1", "app@1.service"},
+		{"app@1.service", "app@1.service"},
+	}
+	for _, tt := range tests {
+		if got := unitName(tt.in); got != tt.want {
+			t.Errorf("unitName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestServiceControl(t *testing.T) {
+	boom := errors.New("exit status 1: Access denied")
+	tests := []struct {
+		name    string
+		state   string // systemctl show output
+		call    func(l *LinuxManager) error
+		verb    string
+		fail    bool // the verb itself returns an error
+		want    string
+		wantErr string
+	}{
+		{name: "start", call: func(l *LinuxManager) error { return l.StartService("app") }, verb: "start", want: "systemctl start --no-block app.service"},
+		{name: "start fails", call: func(l *LinuxManager) error { return l.StartService("app") }, verb: "start", fail: true, wantErr: "Access denied"},
+		{name: "stop", call: func(l *LinuxManager) error { return l.StopService("app") }, verb: "stop", want: "systemctl stop --no-block app.service"},
+		{name: "stop fails", call: func(l *LinuxManager) error { return l.StopService("app") }, verb: "stop", fail: true, wantErr: "Access denied"},
+		{
+			name:  "freeze",
+			state: show("active", "running", "running", 1),
+			call:  func(l *LinuxManager) error { return l.PauseService("app") },
+			verb:  "freeze",
+			want:  "systemctl freeze app.service",
+		},
+		{
+			name:    "freeze fails",
+			state:   show("active", "running", "running", 1),
+			call:    func(l *LinuxManager) error { return l.PauseService("app") },
+			verb:    "freeze",
+			fail:    true,
+			wantErr: "Access denied",
+		},
+		{
+			name:    "freeze stopped unit",
+			state:   show("inactive", "dead", "running", 0),
+			call:    func(l *LinuxManager) error { return l.PauseService("app") },
+			verb:    "freeze",
+			wantErr: "cannot pause service app while STOPPED",
+		},
+		{
+			name:  "thaw",
+			state: show("active", "running", "frozen", 1),
+			call:  func(l *LinuxManager) error { return l.ContinueService("app") },
+			verb:  "thaw",
+			want:  "systemctl thaw app.service",
+		},
+		{
+			name:    "thaw fails",
+			state:   show("active", "running", "frozen", 1),
+			call:    func(l *LinuxManager) error { return l.ContinueService("app") },
+			verb:    "thaw",
+			fail:    true,
+			wantErr: "Access denied",
+		},
+		{
+			name:    "thaw running unit",
+			state:   show("active", "running", "running", 1),
+			call:    func(l *LinuxManager) error { return l.ContinueService("app") },
+			verb:    "thaw",
+			wantErr: "cannot continue service app while RUNNING",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeRunner{out: map[string]string{"show": tt.state}, errs: map[string]error{}}
+			if tt.fail {
+				runner.errs[tt.verb] = boom
+			}
+			l := connected(t, runner)
+
+			err := tt.call(l)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got := runner.calls[len(runner.calls)-1]; got != tt.want {
+				t.Errorf("ran %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotConnected(t *testing.T) {
+	runner := &fakeRunner{errs: map[string]error{"--version": errors.New("exec: \"systemctl\": executable file not found in $PATH")}}
+	l := NewLinuxManager(runner)
+	if err := l.Connect(); err == nil || !strings.Contains(err.Error(), "failed to connect to systemd") {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if err := l.StartService("app"); err == nil {
+		t.Error("StartService() succeeded without a connection")
+	}
+	if _, err := l.GetResourceState("app"); err == nil {
+		t.Error("GetResourceState() succeeded without a connection")
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !windows && !linux
+
+package platform
+
+import "zenlight-support/internal/domain"
+
+func NewManager() domain.ResourceManager {
+	if m := newManagerFromEnv(); m != nil {
+		return m
+	}
+	return NewSimulatorManager(DefaultScenario(), nil)
+}
@@ -5,29 +5,24 @@ package platform
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sync"
-	"time"
 	"unsafe"
 	"zenlight-support/internal/domain"
 	"zenlight-support/pkg/sql"
 
 	"github.com/shirou/gopsutil/v4/process"
 	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
 	"golang.org/x/sys/windows/svc/mgr"
 )
 
-type processHandle struct {
-	proc       *process.Process
-	lastPID    int32
-	lastUpdate time.Time
-}
-
 type WindowsManager struct {
 	mgr          *mgr.Mgr
 	mu           sync.RWMutex
 	processCache map[string]*processHandle
+	procs        *processTracker
+	volumes      *volumeTracker
+	host         *hostCollector
 }
 
 // ExecuteSQLScript implements [domain.ResourceManager].
@@ -36,42 +31,39 @@ func (w *WindowsManager) ExecuteSQLScript(server string, database string, script
 	return executor.Execute(context.Background(), script)
 }
 
-// GetDirectoryMetrics implements [domain.ResourceManager].
-func (w *WindowsManager) GetDirectoryMetrics(path string) (*domain.ResourceMetrics, error) {
-	cleanPath := filepath.Clean(os.ExpandEnv(path))
+// GetProcessMetrics implements [domain.ResourceManager].
+func (w *WindowsManager) GetProcessMetrics(spec domain.ProcessSpec) (*domain.ResourceMetrics, error) {
+	return w.procs.metrics(spec)
+}
 
-	info, err := os.Stat(cleanPath)
-	if err != nil {
-		return nil, err
-	}
-	if !info.IsDir() {
-		return nil, fmt.Errorf("path is not a directory: %s", cleanPath)
-	}
+// GetVolumeMetrics implements [domain.ResourceManager].
+func (w *WindowsManager) GetVolumeMetrics(path string) (*domain.ResourceMetrics, error) {
+	return w.volumes.metrics(path)
+}
 
-	var totalSize int64
-	var lastModified int64
+// GetHostMetrics implements [domain.ResourceManager].
+func (w *WindowsManager) GetHostMetrics() (*domain.HostMetrics, error) {
+	return w.host.metrics()
+}
 
-	err = filepath.Walk(cleanPath, func(_ string, fi os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !fi.IsDir() {
-			totalSize += fi.Size()
-			modTime := fi.ModTime().UnixNano() / 1e6
-			if modTime > lastModified {
-				lastModified = modTime
-			}
-		}
-		return nil
-	})
-	if err != nil {
-		return nil, err
-	}
+// ListVolumes implements [domain.ResourceManager].
+func (w *WindowsManager) ListVolumes() ([]domain.VolumeInfo, error) {
+	return listVolumes()
+}
 
-	return &domain.ResourceMetrics{
-		TotalSize:    totalSize,
-		LastModified: lastModified,
-	}, nil
+// KillProcess implements [domain.ResourceManager].
+func (w *WindowsManager) KillProcess(spec domain.ProcessSpec) error {
+	return w.procs.kill(spec)
+}
+
+// LaunchProcess implements [domain.ResourceManager].
+func (w *WindowsManager) LaunchProcess(spec domain.ProcessSpec, dir string) error {
+	return launchProcess(spec, dir)
+}
+
+// GetDirectoryMetrics implements [domain.ResourceManager].
+func (w *WindowsManager) GetDirectoryMetrics(path string) (*domain.ResourceMetrics, error) {
+	return directoryMetrics(path)
 }
 
 // Connect implements [domain.ServiceManager].
@@ -133,7 +125,6 @@ func (w *WindowsManager) GetServiceMetrics(serviceName string) (*domain.Resource
 	}
 
 	currentPID := int32(status.ProcessId)
-	metrics := &domain.ResourceMetrics{PID: status.ProcessId}
 
 	w.mu.Lock()
 	handle, exists := w.processCache[serviceName]
@@ -144,26 +135,12 @@ func (w *WindowsManager) GetServiceMetrics(serviceName string) (*domain.Resource
 			w.mu.Unlock()
 			return nil, err
 		}
-		handle = &processHandle{proc: p, lastPID: currentPID}
+		handle = newProcessHandle(p)
 		w.processCache[serviceName] = handle
 	}
 	w.mu.Unlock()
 
-	if cpu, err := handle.proc.CPUPercent(); err == nil {
-		metrics.CPUUsage = cpu
-	}
-
-	if mem, err := handle.proc.MemoryInfo(); err == nil {
-		metrics.MemUsage = mem.RSS
-	}
-
-	if metrics.CreateTime == 0 {
-		if createTime, err := handle.proc.CreateTime(); err == nil {
-			metrics.CreateTime = createTime
-		}
-	}
-
-	return metrics, nil
+	return handle.collect(), nil
 }
 
 // GetResourceState implements [domain.ResourceManager].
@@ -187,7 +164,11 @@ func (w *WindowsManager) GetResourceState(serviceName string) (domain.Status, er
 		return domain.STOPPED, err
 	}
 
-	return domain.Status(status.State), nil
+	state := domain.Status(status.State)
+	if !state.IsValid() {
+		return domain.UNKNOWN, fmt.Errorf("unexpected service state %d for %s", status.State, serviceName)
+	}
+	return state, nil
 }
 
 // StartService implements [domain.ServiceManager].
@@ -223,8 +204,40 @@ func (w *WindowsManager) StopService(serviceName string) error {
 	return err
 }
 
+// PauseService implements [domain.ResourceManager].
+func (w *WindowsManager) PauseService(serviceName string) error {
+	return w.control(serviceName, svc.Pause)
+}
+
+// ContinueService implements [domain.ResourceManager].
+func (w *WindowsManager) ContinueService(serviceName string) error {
+	return w.control(serviceName, svc.Continue)
+}
+
+func (w *WindowsManager) control(serviceName string, cmd svc.Cmd) error {
+	w.mu.RLock()
+	m := w.mgr
+	w.mu.RUnlock()
+	if m == nil {
+		return fmt.Errorf("not connected")
+	}
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	_, err = s.Control(cmd)
+	return err
+}
+
 func NewManager() domain.ResourceManager {
+	if m := newManagerFromEnv(); m != nil {
+		return m
+	}
 	return &WindowsManager{
 		processCache: make(map[string]*processHandle),
+		procs:        newProcessTracker(),
+		volumes:      newVolumeTracker(),
+		host:         newHostCollector(),
 	}
 }
@@ -0,0 +1,407 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"zenlight-support/internal/domain"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+type processHandle struct {
+	proc       *process.Process
+	lastPID    int32
+	lastUpdate time.Time
+
+	mu       sync.Mutex
+	children map[int32]*process.Process
+	lastIO   *process.IOCountersStat
+}
+
+func newProcessHandle(p *process.Process) *processHandle {
+	return &processHandle{proc: p, lastPID: p.Pid}
+}
+
+// sampleExtended adds threads, handles, disk I/O rates and listening TCP ports.
+// I/O rates need a previous sample and stay zero on the first call. Callers
+// must hold h.mu.
+func (h *processHandle) sampleExtended(metrics *domain.ResourceMetrics) {
+	if threads, err := h.proc.NumThreads(); err == nil {
+		metrics.Threads += threads
+	}
+	if handles, err := h.proc.NumFDs(); err == nil {
+		metrics.Handles += handles
+	}
+
+	now := time.Now()
+	if io, err := h.proc.IOCounters(); err == nil {
+		if h.lastIO != nil && !h.lastUpdate.IsZero() {
+			if elapsed := now.Sub(h.lastUpdate).Seconds(); elapsed > 0 {
+				metrics.ReadRate += rate(h.lastIO.ReadBytes, io.ReadBytes, elapsed)
+				metrics.WriteRate += rate(h.lastIO.WriteBytes, io.WriteBytes, elapsed)
+			}
+		}
+		h.lastIO = io
+		h.lastUpdate = now
+	}
+
+	if conns, err := h.proc.Connections(); err == nil {
+		for _, c := range conns {
+			if c.Type != syscall.SOCK_STREAM || c.Status != "LISTEN" {
+				continue
+			}
+			if !slices.Contains(metrics.ListenPorts, c.Laddr.Port) {
+				metrics.ListenPorts = append(metrics.ListenPorts, c.Laddr.Port)
+			}
+		}
+		slices.Sort(metrics.ListenPorts)
+	}
+}
+
+func rate(prev, curr uint64, seconds float64) float64 {
+	if curr < prev {
+		return 0
+	}
+	return float64(curr-prev) / seconds
+}
+
+// maxTreeDepth bounds the walk in case PIDs are reused into a loop.
+const maxTreeDepth = 16
+
+// collect measures the main process and every descendant. Child handles are
+// cached by PID and dropped once the child exits.
+func (h *processHandle) collect() *domain.ResourceMetrics {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	metrics := &domain.ResourceMetrics{PID: uint32(h.lastPID)}
+
+	if cpu, err := h.proc.CPUPercent(); err == nil {
+		metrics.CPUUsage = cpu
+	}
+	if mem, err := h.proc.MemoryInfo(); err == nil {
+		metrics.MemUsage = mem.RSS
+	}
+	if createTime, err := h.proc.CreateTime(); err == nil {
+		metrics.CreateTime = createTime
+	}
+	h.sampleExtended(metrics)
+
+	metrics.TreeCPUUsage = metrics.CPUUsage
+	metrics.TreeMemUsage = metrics.MemUsage
+
+	if h.children == nil {
+		h.children = make(map[int32]*process.Process)
+	}
+	seen := make(map[int32]bool)
+
+	type node struct {
+		proc  *process.Process
+		depth int
+	}
+	queue := []node{{proc: h.proc}}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		if parent.depth >= maxTreeDepth {
+			continue
+		}
+
+		kids, err := parent.proc.Children()
+		if err != nil {
+			continue
+		}
+		for _, kid := range kids {
+			if seen[kid.Pid] || kid.Pid == h.lastPID {
+				continue
+			}
+			seen[kid.Pid] = true
+
+			cached, ok := h.children[kid.Pid]
+			if !ok {
+				cached = kid
+				h.children[kid.Pid] = kid
+			}
+
+			child := domain.ProcessMetrics{PID: uint32(cached.Pid), ParentPID: uint32(parent.proc.Pid)}
+			if name, err := cached.Name(); err == nil {
+				child.Name = name
+			}
+			if createTime, err := cached.CreateTime(); err == nil {
+				child.CreateTime = createTime
+			}
+			if cpu, err := cached.CPUPercent(); err == nil {
+				child.CPUUsage = cpu
+			}
+			if mem, err := cached.MemoryInfo(); err == nil {
+				child.MemUsage = mem.RSS
+			}
+
+			metrics.TreeCPUUsage += child.CPUUsage
+			metrics.TreeMemUsage += child.MemUsage
+			metrics.Children = append(metrics.Children, child)
+			queue = append(queue, node{proc: cached, depth: parent.depth + 1})
+		}
+	}
+
+	for pid := range h.children {
+		if !seen[pid] {
+			delete(h.children, pid)
+		}
+	}
+	metrics.ChildCount = len(metrics.Children)
+
+	return metrics
+}
+
+// processTracker finds plain processes by name, path or command line. Handles
+// are kept per PID so CPU usage is measured against the previous sample.
+// Processes that did not match a spec are remembered too, so the name, path
+// and command line of every other process are not read on each poll.
+type processTracker struct {
+	mu       sync.Mutex
+	handles  map[int32]*processHandle
+	misses   map[int32]*processMiss
+	patterns map[string]*regexp.Regexp
+}
+
+// processMiss records when a process last failed to match each spec. The
+// create time tells a reused PID apart from the process that was checked.
+// Misses expire since a process that calls exec keeps its PID and create time
+// but changes its name and command line.
+type processMiss struct {
+	createTime int64
+	specs      map[string]time.Time
+}
+
+const missTTL = time.Minute
+
+func newProcessTracker() *processTracker {
+	return &processTracker{
+		handles:  make(map[int32]*processHandle),
+		misses:   make(map[int32]*processMiss),
+		patterns: make(map[string]*regexp.Regexp),
+	}
+}
+
+// specKey identifies the match criteria of spec.
+func specKey(spec domain.ProcessSpec) string {
+	return spec.Name + "\x00" + spec.Executable + "\x00" + spec.CommandPattern
+}
+
+func (t *processTracker) metrics(spec domain.ProcessSpec) (*domain.ResourceMetrics, error) {
+	procs, err := t.find(spec)
+	if err != nil {
+		return nil, err
+	}
+	if len(procs) == 0 {
+		return nil, nil
+	}
+
+	metrics := &domain.ResourceMetrics{}
+	for _, h := range procs {
+		p := h.proc
+		metrics.PIDs = append(metrics.PIDs, uint32(p.Pid))
+
+		if cpu, err := p.CPUPercent(); err == nil {
+			metrics.CPUUsage += cpu
+		}
+		if mem, err := p.MemoryInfo(); err == nil {
+			metrics.MemUsage += mem.RSS
+		}
+		if createTime, err := p.CreateTime(); err == nil {
+			if metrics.CreateTime == 0 || createTime < metrics.CreateTime {
+				metrics.CreateTime = createTime
+				metrics.PID = uint32(p.Pid)
+			}
+		}
+
+		h.mu.Lock()
+		h.sampleExtended(metrics)
+		h.mu.Unlock()
+	}
+	if metrics.PID == 0 {
+		metrics.PID = metrics.PIDs[0]
+	}
+
+	return metrics, nil
+}
+
+func (t *processTracker) kill(spec domain.ProcessSpec) error {
+	if !spec.AllowKill {
+		return fmt.Errorf("killing is not allowed for this process")
+	}
+
+	procs, err := t.find(spec)
+	if err != nil {
+		return err
+	}
+	if len(procs) == 0 {
+		return fmt.Errorf("no matching process is running")
+	}
+
+	var errs []string
+	for _, h := range procs {
+		if err := h.proc.Kill(); err != nil {
+			errs = append(errs, fmt.Sprintf("pid %d: %s", h.lastPID, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to kill process: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func launchProcess(spec domain.ProcessSpec, dir string) error {
+	if spec.Executable == "" {
+		return fmt.Errorf("no executable configured to launch")
+	}
+
+	cmd := exec.Command(filepath.Clean(os.ExpandEnv(spec.Executable)), spec.LaunchArgs...)
+	if dir != "" {
+		cmd.Dir = filepath.Clean(os.ExpandEnv(dir))
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to launch process: %w", err)
+	}
+
+	// Reap the child once it exits, it is monitored through the process list
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+func (t *processTracker) find(spec domain.ProcessSpec) ([]*processHandle, error) {
+	if spec.IsEmpty() {
+		return nil, fmt.Errorf("process match criteria are empty")
+	}
+
+	var pattern *regexp.Regexp
+	if spec.CommandPattern != "" {
+		var err error
+		if pattern, err = t.pattern(spec.CommandPattern); err != nil {
+			return nil, err
+		}
+	}
+
+	pids, err := process.Pids()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Drop handles and misses of processes that have exited
+	alive := make(map[int32]bool, len(pids))
+	for _, pid := range pids {
+		alive[pid] = true
+	}
+	for pid := range t.handles {
+		if !alive[pid] {
+			delete(t.handles, pid)
+		}
+	}
+	for pid := range t.misses {
+		if !alive[pid] {
+			delete(t.misses, pid)
+		}
+	}
+
+	key := specKey(spec)
+	now := time.Now()
+	var matched []*processHandle
+	for _, pid := range pids {
+		if h, ok := t.handles[pid]; ok {
+			if matchProcess(h.proc, spec, pattern) {
+				matched = append(matched, h)
+			}
+			continue
+		}
+
+		p, err := process.NewProcess(pid)
+		if err != nil {
+			continue
+		}
+
+		// Without a create time a reused PID cannot be told apart, such
+		// processes are checked every time
+		createTime, err := p.CreateTime()
+		known := err == nil
+		miss, ok := t.misses[pid]
+		if known && ok && miss.createTime == createTime && now.Sub(miss.specs[key]) < missTTL {
+			continue
+		}
+
+		if !matchProcess(p, spec, pattern) {
+			if !known {
+				continue
+			}
+			if !ok || miss.createTime != createTime {
+				miss = &processMiss{createTime: createTime, specs: make(map[string]time.Time)}
+				t.misses[pid] = miss
+			}
+			miss.specs[key] = now
+			continue
+		}
+
+		h := newProcessHandle(p)
+		t.handles[pid] = h
+		matched = append(matched, h)
+	}
+
+	return matched, nil
+}
+
+func (t *processTracker) pattern(expr string) (*regexp.Regexp, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if re, ok := t.patterns[expr]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command pattern: %w", err)
+	}
+	t.patterns[expr] = re
+	return re, nil
+}
+
+func matchProcess(p *process.Process, spec domain.ProcessSpec, pattern *regexp.Regexp) bool {
+	if spec.Name != "" {
+		name, err := p.Name()
+		if err != nil || !samePath(name, spec.Name) {
+			return false
+		}
+	}
+	if spec.Executable != "" {
+		exe, err := p.Exe()
+		if err != nil || !samePath(exe, filepath.Clean(os.ExpandEnv(spec.Executable))) {
+			return false
+		}
+	}
+	if pattern != nil {
+		cmdline, err := p.Cmdline()
+		if err != nil || !pattern.MatchString(cmdline) {
+			return false
+		}
+	}
+	return true
+}
+
+// samePath compares names the way the host file system does.
+func samePath(a, b string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
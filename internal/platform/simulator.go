@@ -0,0 +1,682 @@
+package platform
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"sync"
+	"time"
+	"zenlight-support/internal/domain"
+	"zenlight-support/pkg/sql"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ScenarioEnv points NewManager at a simulator scenario file instead of the native backend.
+const ScenarioEnv = "ZENLIGHT_SCENARIO"
+
+type SimState string
+
+const (
+	SimRunning SimState = "running"
+	SimStopped SimState = "stopped"
+	SimPaused  SimState = "paused"
+)
+
+func (st SimState) status() domain.Status {
+	switch st {
+	case SimStopped:
+		return domain.STOPPED
+	case SimPaused:
+		return domain.PAUSED
+	default:
+		return domain.RUNNING
+	}
+}
+
+type SimOperation string
+
+const (
+	OpStart   SimOperation = "start"
+	OpStop    SimOperation = "stop"
+	OpPause   SimOperation = "pause"
+	OpResume  SimOperation = "continue"
+	OpState   SimOperation = "state"
+	OpMetrics SimOperation = "metrics"
+	OpKill    SimOperation = "kill"
+	OpLaunch  SimOperation = "launch"
+	OpSQL     SimOperation = "sql"
+)
+
+// Curve describes a synthetic signal: base + slope*minutes + amplitude*sin(2πt/period) ± noise.
+type Curve struct {
+	Base      float64       `yaml:"base"`
+	Slope     float64       `yaml:"slope_per_minute"`
+	Amplitude float64       `yaml:"amplitude"`
+	Period    time.Duration `yaml:"period"`
+	Noise     float64       `yaml:"noise"`
+	Min       float64       `yaml:"min"`
+	Max       float64       `yaml:"max"`
+}
+
+type InjectedError struct {
+	Op      SimOperation  `yaml:"op"`
+	Message string        `yaml:"message"`
+	From    time.Duration `yaml:"from"`
+	Until   time.Duration `yaml:"until"`
+	Count   int           `yaml:"count"`
+}
+
+type Crash struct {
+	At time.Duration `yaml:"at"`
+}
+
+type ServiceScenario struct {
+	Initial    SimState        `yaml:"initial"`
+	StartDelay time.Duration   `yaml:"start_delay"`
+	StopDelay  time.Duration   `yaml:"stop_delay"`
+	PauseDelay time.Duration   `yaml:"pause_delay"`
+	CPU        Curve           `yaml:"cpu"`
+	MemoryMB   Curve           `yaml:"memory_mb"`
+	Errors     []InjectedError `yaml:"errors"`
+	Crashes    []Crash         `yaml:"crashes"`
+	Children   []ChildScenario `yaml:"children"`
+
+	Threads     int      `yaml:"threads"`
+	Handles     int      `yaml:"handles"`
+	ListenPorts []uint32 `yaml:"listen_ports"`
+	ReadKBps    Curve    `yaml:"read_kbps"`
+	WriteKBps   Curve    `yaml:"write_kbps"`
+}
+
+// ChildScenario spawns Count worker processes below the main service process.
+type ChildScenario struct {
+	Name     string `yaml:"name"`
+	Count    int    `yaml:"count"`
+	CPU      Curve  `yaml:"cpu"`
+	MemoryMB Curve  `yaml:"memory_mb"`
+}
+
+type DirectoryScenario struct {
+	TotalSize int64 `yaml:"total_size"`
+	FileCount int64 `yaml:"file_count"`
+	Growth    int64 `yaml:"growth_per_minute"`
+}
+
+type VolumeScenario struct {
+	TotalGB       float64 `yaml:"total_gb"`
+	UsedGB        float64 `yaml:"used_gb"`
+	GrowthPerHour float64 `yaml:"growth_gb_per_hour"`
+}
+
+type Scenario struct {
+	Seed        uint64                       `yaml:"seed"`
+	Default     ServiceScenario              `yaml:"default"`
+	Services    map[string]ServiceScenario   `yaml:"services"`
+	Processes   map[string]ServiceScenario   `yaml:"processes"`
+	Directories map[string]DirectoryScenario `yaml:"directories"`
+	Volumes     map[string]VolumeScenario    `yaml:"volumes"`
+	Errors      []InjectedError              `yaml:"errors"`
+}
+
+// DefaultScenario keeps every unknown service running with light, steady load.
+func DefaultScenario() *Scenario {
+	return &Scenario{
+		Seed: 1,
+		Default: ServiceScenario{
+			Initial:    SimRunning,
+			StartDelay: 2 * time.Second,
+			StopDelay:  time.Second,
+			PauseDelay: 500 * time.Millisecond,
+			CPU:        Curve{Base: 2.5, Amplitude: 1.5, Period: time.Minute, Noise: 0.5, Max: 100},
+			MemoryMB:   Curve{Base: 100, Amplitude: 5, Period: 5 * time.Minute},
+		},
+	}
+}
+
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+
+	scn := DefaultScenario()
+	if err := yaml.Unmarshal(data, scn); err != nil {
+		return nil, fmt.Errorf("invalid scenario format: %w", err)
+	}
+	if err := scn.validate(); err != nil {
+		return nil, err
+	}
+	return scn, nil
+}
+
+func (s *Scenario) validate() error {
+	check := func(name string, svc ServiceScenario) error {
+		switch svc.Initial {
+		case "", SimRunning, SimStopped, SimPaused:
+		default:
+			return fmt.Errorf("service %s: invalid initial state %q", name, svc.Initial)
+		}
+		if svc.StartDelay < 0 || svc.StopDelay < 0 || svc.PauseDelay < 0 {
+			return fmt.Errorf("service %s: delays must not be negative", name)
+		}
+		return nil
+	}
+
+	if err := check("default", s.Default); err != nil {
+		return err
+	}
+	for name, svc := range s.Services {
+		if err := check(name, svc); err != nil {
+			return err
+		}
+	}
+	for name, proc := range s.Processes {
+		if err := check(name, proc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type simService struct {
+	spec      ServiceScenario
+	state     domain.Status
+	target    domain.Status
+	settleAt  time.Time
+	pid       uint32
+	startedAt time.Time
+	crashed   []bool
+	errUsed   []int
+}
+
+// SimulatorManager implements [domain.ResourceManager] from a YAML scenario so the
+// watcher and UI can be exercised without a real service control manager.
+type SimulatorManager struct {
+	scn       *Scenario
+	now       func() time.Time
+	mu        sync.Mutex
+	rnd       *rand.Rand
+	epoch     time.Time
+	connected bool
+	services  map[string]*simService
+	globalErr []int
+	nextPID   uint32
+	volumes   *volumeTracker
+	host      *hostCollector
+}
+
+func NewSimulatorManager(scn *Scenario, now func() time.Time) *SimulatorManager {
+	if scn == nil {
+		scn = DefaultScenario()
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &SimulatorManager{
+		scn:       scn,
+		now:       now,
+		rnd:       rand.New(rand.NewPCG(scn.Seed, scn.Seed)),
+		epoch:     now(),
+		services:  make(map[string]*simService),
+		globalErr: make([]int, len(scn.Errors)),
+		nextPID:   4000,
+		volumes:   newVolumeTracker(),
+		host:      newHostCollector(),
+	}
+}
+
+// Connect implements [domain.ResourceManager].
+func (s *SimulatorManager) Connect() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connected = true
+	return nil
+}
+
+// Disconnect implements [domain.ResourceManager].
+func (s *SimulatorManager) Disconnect() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connected = false
+	return nil
+}
+
+// GetResourceState implements [domain.ResourceManager].
+func (s *SimulatorManager) GetResourceState(serviceName string) (domain.Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	svc, err := s.service(serviceName, OpState)
+	if err != nil {
+		return domain.STOPPED, err
+	}
+	return svc.status(), nil
+}
+
+// GetServiceMetrics implements [domain.ResourceManager].
+func (s *SimulatorManager) GetServiceMetrics(serviceName string) (*domain.ResourceMetrics, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	svc, err := s.service(serviceName, OpMetrics)
+	if err != nil {
+		return nil, err
+	}
+	if !svc.status().IsActive() {
+		return nil, nil
+	}
+
+	uptime := s.now().Sub(svc.startedAt)
+	cpu := s.sample(svc.spec.CPU, uptime)
+	mem := s.sample(svc.spec.MemoryMB, uptime)
+	if svc.status() == domain.PAUSED {
+		cpu = 0
+	}
+
+	metrics := &domain.ResourceMetrics{
+		PID:          svc.pid,
+		CreateTime:   svc.startedAt.UnixMilli(),
+		CPUUsage:     cpu,
+		MemUsage:     uint64(mem * 1024 * 1024),
+		TreeCPUUsage: cpu,
+		TreeMemUsage: uint64(mem * 1024 * 1024),
+	}
+	s.sampleExtended(svc, uptime, metrics)
+
+	childPID := svc.pid
+	for _, group := range svc.spec.Children {
+		for range group.Count {
+			childPID++
+			child := domain.ProcessMetrics{
+				PID:        childPID,
+				ParentPID:  svc.pid,
+				Name:       group.Name,
+				CreateTime: metrics.CreateTime,
+				CPUUsage:   s.sample(group.CPU, uptime),
+				MemUsage:   uint64(s.sample(group.MemoryMB, uptime) * 1024 * 1024),
+			}
+			if svc.status() == domain.PAUSED {
+				child.CPUUsage = 0
+			}
+			metrics.TreeCPUUsage += child.CPUUsage
+			metrics.TreeMemUsage += child.MemUsage
+			metrics.Children = append(metrics.Children, child)
+		}
+	}
+	metrics.ChildCount = len(metrics.Children)
+
+	return metrics, nil
+}
+
+// GetDirectoryMetrics implements [domain.ResourceManager].
+func (s *SimulatorManager) GetDirectoryMetrics(path string) (*domain.ResourceMetrics, error) {
+	dir, ok := s.scn.Directories[path]
+	if !ok {
+		return directoryMetrics(path)
+	}
+
+	now := s.now()
+	return &domain.ResourceMetrics{
+		TotalSize:    dir.TotalSize + int64(now.Sub(s.epoch).Minutes()*float64(dir.Growth)),
+		FileCount:    dir.FileCount,
+		LastModified: now.UnixMilli(),
+	}, nil
+}
+
+// GetProcessMetrics implements [domain.ResourceManager].
+func (s *SimulatorManager) GetProcessMetrics(spec domain.ProcessSpec) (*domain.ResourceMetrics, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proc, err := s.process(spec, OpMetrics)
+	if err != nil {
+		return nil, err
+	}
+	if !proc.status().IsActive() {
+		return nil, nil
+	}
+
+	uptime := s.now().Sub(proc.startedAt)
+	metrics := &domain.ResourceMetrics{
+		PID:        proc.pid,
+		PIDs:       []uint32{proc.pid},
+		CreateTime: proc.startedAt.UnixMilli(),
+		CPUUsage:   s.sample(proc.spec.CPU, uptime),
+		MemUsage:   uint64(s.sample(proc.spec.MemoryMB, uptime) * 1024 * 1024),
+	}
+	s.sampleExtended(proc, uptime, metrics)
+	return metrics, nil
+}
+
+func (s *SimulatorManager) sampleExtended(svc *simService, uptime time.Duration, metrics *domain.ResourceMetrics) {
+	metrics.Threads = int32(svc.spec.Threads)
+	metrics.Handles = int32(svc.spec.Handles)
+	metrics.ListenPorts = svc.spec.ListenPorts
+	metrics.ReadRate = s.sample(svc.spec.ReadKBps, uptime) * 1024
+	metrics.WriteRate = s.sample(svc.spec.WriteKBps, uptime) * 1024
+}
+
+// KillProcess implements [domain.ResourceManager].
+func (s *SimulatorManager) KillProcess(spec domain.ProcessSpec) error {
+	if !spec.AllowKill {
+		return fmt.Errorf("killing is not allowed for this process")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proc, err := s.process(spec, OpKill)
+	if err != nil {
+		return err
+	}
+	if !proc.status().IsActive() {
+		return fmt.Errorf("no matching process is running")
+	}
+	proc.state, proc.target, proc.pid = domain.STOPPED, domain.STOPPED, 0
+	return nil
+}
+
+// LaunchProcess implements [domain.ResourceManager].
+func (s *SimulatorManager) LaunchProcess(spec domain.ProcessSpec, dir string) error {
+	if spec.Executable == "" {
+		return fmt.Errorf("no executable configured to launch")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proc, err := s.process(spec, OpLaunch)
+	if err != nil {
+		return err
+	}
+	if proc.target == domain.STOPPED {
+		proc.target = domain.RUNNING
+		proc.settleAt = s.now().Add(proc.spec.StartDelay)
+		s.advance(proc)
+	}
+	return nil
+}
+
+// GetVolumeMetrics implements [domain.ResourceManager].
+func (s *SimulatorManager) GetVolumeMetrics(path string) (*domain.ResourceMetrics, error) {
+	vol, ok := s.scn.Volumes[path]
+	if !ok {
+		return s.volumes.metrics(path)
+	}
+
+	const gb = 1 << 30
+	hours := s.now().Sub(s.epoch).Hours()
+	total := uint64(vol.TotalGB * gb)
+	used := min(uint64((vol.UsedGB+vol.GrowthPerHour*hours)*gb), total)
+
+	var percent float64
+	if total > 0 {
+		percent = float64(used) / float64(total) * 100
+	}
+
+	return &domain.ResourceMetrics{
+		VolumeTotal: total,
+		VolumeUsed:  used,
+		VolumeFree:  total - used,
+		UsedPercent: percent,
+		GrowthRate:  vol.GrowthPerHour * gb,
+	}, nil
+}
+
+// GetHostMetrics implements [domain.ResourceManager].
+func (s *SimulatorManager) GetHostMetrics() (*domain.HostMetrics, error) {
+	return s.host.metrics()
+}
+
+// ListVolumes implements [domain.ResourceManager].
+func (s *SimulatorManager) ListVolumes() ([]domain.VolumeInfo, error) {
+	if len(s.scn.Volumes) == 0 {
+		return listVolumes()
+	}
+
+	var volumes []domain.VolumeInfo
+	for path := range s.scn.Volumes {
+		m, err := s.GetVolumeMetrics(path)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, domain.VolumeInfo{
+			Device:      path,
+			Mountpoint:  path,
+			Fstype:      "simulated",
+			Total:       m.VolumeTotal,
+			Used:        m.VolumeUsed,
+			Free:        m.VolumeFree,
+			UsedPercent: m.UsedPercent,
+		})
+	}
+	slices.SortFunc(volumes, func(a, b domain.VolumeInfo) int { return cmp.Compare(a.Mountpoint, b.Mountpoint) })
+	return volumes, nil
+}
+
+// StartService implements [domain.ResourceManager].
+func (s *SimulatorManager) StartService(serviceName string) error {
+	return s.transition(serviceName, OpStart, domain.START_PENDING, domain.RUNNING)
+}
+
+// StopService implements [domain.ResourceManager].
+func (s *SimulatorManager) StopService(serviceName string) error {
+	return s.transition(serviceName, OpStop, domain.STOP_PENDING, domain.STOPPED)
+}
+
+// PauseService implements [domain.ResourceManager].
+func (s *SimulatorManager) PauseService(serviceName string) error {
+	return s.transition(serviceName, OpPause, domain.PAUSE_PENDING, domain.PAUSED)
+}
+
+// ContinueService implements [domain.ResourceManager].
+func (s *SimulatorManager) ContinueService(serviceName string) error {
+	return s.transition(serviceName, OpResume, domain.CONTINUE_PENDING, domain.RUNNING)
+}
+
+// transition schedules a move to target through the pending state op enters
+// first. It fails like the SCM when the current state cannot move to pending,
+// such as pausing a stopped service, or the service is already heading to target.
+func (s *SimulatorManager) transition(serviceName string, op SimOperation, pending, target domain.Status) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	svc, err := s.service(serviceName, op)
+	if err != nil {
+		return err
+	}
+
+	current := svc.status()
+	if svc.target == target || !current.CanTransitionTo(pending) {
+		return fmt.Errorf("cannot %s service %s while %s", op, serviceName, current)
+	}
+
+	delay := svc.spec.PauseDelay
+	switch target {
+	case domain.STOPPED:
+		delay = svc.spec.StopDelay
+	case domain.RUNNING:
+		if current == domain.STOPPED {
+			delay = svc.spec.StartDelay
+		}
+	}
+
+	svc.target = target
+	svc.settleAt = s.now().Add(delay)
+	s.advance(svc)
+	return nil
+}
+
+// ExecuteSQLScript implements [domain.ResourceManager].
+func (s *SimulatorManager) ExecuteSQLScript(server string, database string, script string) (*sql.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.connected {
+		return nil, fmt.Errorf("not connected")
+	}
+	if err := s.injected(s.scn.Errors, s.globalErr, OpSQL); err != nil {
+		return nil, err
+	}
+	return &sql.Result{ExecutionTime: time.Duration(0).String()}, nil
+}
+
+// service returns the simulated service after applying pending transitions,
+// crashes and any error injected for op. Callers must hold s.mu.
+func (s *SimulatorManager) service(name string, op SimOperation) (*simService, error) {
+	return s.lookup(name, s.scn.Services, name, op)
+}
+
+// process is the [SimulatorManager.service] counterpart for process resources,
+// keyed by executable name, falling back to path or command pattern.
+func (s *SimulatorManager) process(spec domain.ProcessSpec, op SimOperation) (*simService, error) {
+	name := cmp.Or(spec.Name, spec.Executable, spec.CommandPattern)
+	if name == "" {
+		return nil, fmt.Errorf("process match criteria are empty")
+	}
+	return s.lookup("process:"+name, s.scn.Processes, name, op)
+}
+
+func (s *SimulatorManager) lookup(key string, specs map[string]ServiceScenario, name string, op SimOperation) (*simService, error) {
+	if !s.connected {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	svc, ok := s.services[key]
+	if !ok {
+		spec, found := specs[name]
+		if !found {
+			spec = s.scn.Default
+		}
+		svc = &simService{
+			spec:    spec,
+			state:   domain.STOPPED,
+			target:  domain.STOPPED,
+			crashed: make([]bool, len(spec.Crashes)),
+			errUsed: make([]int, len(spec.Errors)),
+		}
+		if initial := spec.Initial.status(); initial != domain.STOPPED {
+			s.run(svc, s.epoch)
+			svc.state, svc.target = initial, initial
+		}
+		s.services[key] = svc
+	}
+
+	s.advance(svc)
+
+	if err := s.injected(svc.spec.Errors, svc.errUsed, op); err != nil {
+		return nil, err
+	}
+	if err := s.injected(s.scn.Errors, s.globalErr, op); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+func (s *SimulatorManager) advance(svc *simService) {
+	now := s.now()
+
+	if svc.state != svc.target && !now.Before(svc.settleAt) {
+		switch {
+		case svc.target == domain.STOPPED:
+			svc.pid = 0
+		case svc.state == domain.STOPPED:
+			s.run(svc, svc.settleAt)
+		}
+		svc.state = svc.target
+	}
+
+	elapsed := now.Sub(s.epoch)
+	for i, crash := range svc.spec.Crashes {
+		if svc.crashed[i] || elapsed < crash.At {
+			continue
+		}
+		svc.crashed[i] = true
+		if svc.state.IsActive() {
+			slog.Debug("simulator: service crashed", "pid", svc.pid, "at", crash.At.String())
+			svc.state = domain.STOPPED
+			svc.target = domain.STOPPED
+			svc.pid = 0
+		}
+	}
+}
+
+func (s *SimulatorManager) run(svc *simService, at time.Time) {
+	s.nextPID += 4
+	svc.pid = s.nextPID
+	svc.startedAt = at
+}
+
+// status reports the pending state while a transition is in flight.
+func (svc *simService) status() domain.Status {
+	if svc.state == svc.target {
+		return svc.state
+	}
+	switch svc.target {
+	case domain.STOPPED:
+		return domain.STOP_PENDING
+	case domain.PAUSED:
+		return domain.PAUSE_PENDING
+	}
+	if svc.state == domain.PAUSED {
+		return domain.CONTINUE_PENDING
+	}
+	return domain.START_PENDING
+}
+
+func (s *SimulatorManager) injected(rules []InjectedError, used []int, op SimOperation) error {
+	elapsed := s.now().Sub(s.epoch)
+	for i, rule := range rules {
+		if rule.Op != op || elapsed < rule.From {
+			continue
+		}
+		if rule.Until > 0 && elapsed >= rule.Until {
+			continue
+		}
+		if rule.Count > 0 && used[i] >= rule.Count {
+			continue
+		}
+		used[i]++
+		return fmt.Errorf("%s", rule.Message)
+	}
+	return nil
+}
+
+func (s *SimulatorManager) sample(c Curve, elapsed time.Duration) float64 {
+	v := c.Base + c.Slope*elapsed.Minutes()
+	if c.Period > 0 {
+		v += c.Amplitude * math.Sin(2*math.Pi*float64(elapsed)/float64(c.Period))
+	}
+	if c.Noise > 0 {
+		v += (s.rnd.Float64()*2 - 1) * c.Noise
+	}
+	if v < c.Min {
+		v = c.Min
+	}
+	if c.Max > 0 && v > c.Max {
+		v = c.Max
+	}
+	return v
+}
+
+// newManagerFromEnv returns a simulator when ScenarioEnv is set, or nil otherwise.
+func newManagerFromEnv() domain.ResourceManager {
+	path := os.Getenv(ScenarioEnv)
+	if path == "" {
+		return nil
+	}
+
+	scn, err := LoadScenario(path)
+	if err != nil {
+		slog.Error("Failed to load simulator scenario", slog.String("path", path), slog.String("error", err.Error()))
+		return nil
+	}
+
+	slog.Info("Using simulator scenario", slog.String("path", path))
+	return NewSimulatorManager(scn, nil)
+}
@@ -0,0 +1,252 @@
+package platform
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"zenlight-support/internal/domain"
+)
+
+const testScenario = `
+seed: 7
+services:
+  app:
+    initial: stopped
+    start_delay: 2s
+    stop_delay: 1s
+    pause_delay: 500ms
+    crashes:
+      - at: 30s
+    errors:
+      - op: start
+        message: access denied
+        from: 10s
+        until: 20s
+        count: 1
+  api:
+    initial: running
+    cpu: {base: 10, amplitude: 5, period: 1m, max: 100}
+    memory_mb: {base: 100, slope_per_minute: 10}
+`
+
+// fakeClock is a manually advanced time source.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func loadTestScenario(t *testing.T, data string) (*Scenario, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadScenario(path)
+}
+
+func newTestSimulator(t *testing.T) (*SimulatorManager, *fakeClock) {
+	t.Helper()
+	scn, err := loadTestScenario(t, testScenario)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	sim := NewSimulatorManager(scn, clock.now)
+	if err := sim.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return sim, clock
+}
+
+func TestSimulatorStateSequence(t *testing.T) {
+	sim, clock := newTestSimulator(t)
+
+	start := func() error { return sim.StartService("app") }
+	stop := func() error { return sim.StopService("app") }
+	pause := func() error { return sim.PauseService("app") }
+	resume := func() error { return sim.ContinueService("app") }
+
+	steps := []struct {
+		name    string
+		after   time.Duration // clock advance before the step
+		op      func() error
+		wantErr string
+		want    domain.Status
+	}{
+		{name: "initially stopped", want: domain.STOPPED},
+		{name: "pause while stopped", op: pause, wantErr: "cannot pause service app while STOPPED", want: domain.STOPPED},
+		{name: "start", op: start, want: domain.START_PENDING},
+		{name: "start again while starting", op: start, wantErr: "cannot start service app while START_PENDING", want: domain.START_PENDING},
+		{name: "still starting", after: 1999 * time.Millisecond, want: domain.START_PENDING},
+		{name: "started after start_delay", after: time.Millisecond, want: domain.RUNNING},
+		{name: "pause", op: pause, want: domain.PAUSE_PENDING},
+		{name: "paused after pause_delay", after: 500 * time.Millisecond, want: domain.PAUSED},
+		{name: "pause again", op: pause, wantErr: "cannot pause service app while PAUSED", want: domain.PAUSED},
+		{name: "continue", op: resume, want: domain.CONTINUE_PENDING},
+		{name: "resumed after pause_delay", after: 500 * time.Millisecond, want: domain.RUNNING},
+		{name: "continue while running", op: resume, wantErr: "cannot continue service app while RUNNING", want: domain.RUNNING},
+		{name: "stop", op: stop, want: domain.STOP_PENDING},
+		{name: "stopped after stop_delay", after: time.Second, want: domain.STOPPED},
+	}
+
+	for _, st := range steps {
+		clock.advance(st.after)
+		if st.op != nil {
+			err := st.op()
+			if st.wantErr == "" && err != nil {
+				t.Fatalf("%s: error = %v", st.name, err)
+			}
+			if st.wantErr != "" && (err == nil || err.Error() != st.wantErr) {
+				t.Fatalf("%s: error = %v, want %q", st.name, err, st.wantErr)
+			}
+		}
+		got, err := sim.GetResourceState("app")
+		if err != nil {
+			t.Fatalf("%s: GetResourceState() error = %v", st.name, err)
+		}
+		if got != st.want {
+			t.Fatalf("%s: state = %s, want %s", st.name, got, st.want)
+		}
+	}
+}
+
+func TestSimulatorInjectedError(t *testing.T) {
+	sim, clock := newTestSimulator(t)
+
+	clock.advance(10 * time.Second)
+	if err := sim.StartService("app"); err == nil || err.Error() != "access denied" {
+		t.Fatalf("StartService() error = %v, want the injected error", err)
+	}
+	if _, err := sim.GetResourceState("app"); err != nil {
+		t.Fatalf("GetResourceState() error = %v, the error is injected for start only", err)
+	}
+
+	// count: 1 is used up by the first attempt
+	if err := sim.StartService("app"); err != nil {
+		t.Fatalf("second StartService() error = %v", err)
+	}
+}
+
+func TestSimulatorInjectedErrorWindow(t *testing.T) {
+	tests := []struct {
+		name string
+		at   time.Duration
+	}{
+		{name: "before from", at: 10*time.Second - time.Millisecond},
+		{name: "at until", at: 20 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim, clock := newTestSimulator(t)
+			clock.advance(tt.at)
+			if err := sim.StartService("app"); err != nil {
+				t.Fatalf("StartService() error = %v, want none outside the window", err)
+			}
+		})
+	}
+}
+
+func TestSimulatorCrash(t *testing.T) {
+	sim, clock := newTestSimulator(t)
+
+	if err := sim.StartService("app"); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.advance(30*time.Second - time.Millisecond)
+	if got, _ := sim.GetResourceState("app"); got != domain.RUNNING {
+		t.Fatalf("state before the crash = %s, want RUNNING", got)
+	}
+	m, err := sim.GetServiceMetrics("app")
+	if err != nil || m == nil || m.PID == 0 {
+		t.Fatalf("GetServiceMetrics() = %+v, %v, want a running process", m, err)
+	}
+
+	clock.advance(time.Millisecond)
+	if got, _ := sim.GetResourceState("app"); got != domain.STOPPED {
+		t.Fatalf("state at the crash = %s, want STOPPED", got)
+	}
+	if m, err := sim.GetServiceMetrics("app"); err != nil || m != nil {
+		t.Fatalf("GetServiceMetrics() after the crash = %+v, %v, want nil", m, err)
+	}
+
+	// A crash happens once, the service can be started again
+	if err := sim.StartService("app"); err != nil {
+		t.Fatal(err)
+	}
+	clock.advance(time.Minute)
+	if got, _ := sim.GetResourceState("app"); got != domain.RUNNING {
+		t.Fatalf("state after restarting = %s, want RUNNING", got)
+	}
+}
+
+func TestSimulatorCurves(t *testing.T) {
+	sim, clock := newTestSimulator(t)
+
+	tests := []struct {
+		at      time.Duration
+		wantCPU float64
+		wantMB  float64
+	}{
+		{at: 0, wantCPU: 10, wantMB: 100},
+		{at: 15 * time.Second, wantCPU: 15, wantMB: 102.5},
+		{at: 30 * time.Second, wantCPU: 10, wantMB: 105},
+		{at: 45 * time.Second, wantCPU: 5, wantMB: 107.5},
+		{at: 2 * time.Minute, wantCPU: 10, wantMB: 120},
+	}
+
+	var elapsed time.Duration
+	for _, tt := range tests {
+		clock.advance(tt.at - elapsed)
+		elapsed = tt.at
+
+		m, err := sim.GetServiceMetrics("api")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(m.CPUUsage-tt.wantCPU) > 1e-9 {
+			t.Errorf("CPU at %s = %v, want %v", tt.at, m.CPUUsage, tt.wantCPU)
+		}
+		if want := uint64(tt.wantMB * 1024 * 1024); m.MemUsage != want {
+			t.Errorf("memory at %s = %d, want %d", tt.at, m.MemUsage, want)
+		}
+	}
+
+	// A paused service keeps its memory but uses no CPU
+	if err := sim.PauseService("api"); err != nil {
+		t.Fatal(err)
+	}
+	m, err := sim.GetServiceMetrics("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.CPUUsage != 0 || m.MemUsage == 0 {
+		t.Errorf("paused metrics = CPU %v, memory %d, want no CPU", m.CPUUsage, m.MemUsage)
+	}
+}
+
+func TestLoadScenarioErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "invalid initial state", data: "services:\n  app:\n    initial: sleeping\n", wantErr: `invalid initial state "sleeping"`},
+		{name: "negative delay", data: "services:\n  app:\n    start_delay: -1s\n", wantErr: "delays must not be negative"},
+		{name: "not yaml", data: "services: [", wantErr: "invalid scenario format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadTestScenario(t, tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadScenario() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"zenlight-support/internal/domain"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+// growthWindow is how far back used space samples are kept to compute the growth rate.
+const growthWindow = time.Hour
+
+type usageSample struct {
+	at   time.Time
+	used uint64
+}
+
+// volumeTracker reports volume usage and the rate at which used space grows.
+type volumeTracker struct {
+	mu      sync.Mutex
+	samples map[string][]usageSample
+}
+
+func newVolumeTracker() *volumeTracker {
+	return &volumeTracker{samples: make(map[string][]usageSample)}
+}
+
+func (t *volumeTracker) metrics(path string) (*domain.ResourceMetrics, error) {
+	mount := filepath.Clean(os.ExpandEnv(path))
+
+	usage, err := disk.Usage(mount)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	t.mu.Lock()
+	samples := append(t.samples[mount], usageSample{at: now, used: usage.Used})
+	for len(samples) > 1 && now.Sub(samples[0].at) > growthWindow {
+		samples = samples[1:]
+	}
+	t.samples[mount] = samples
+	t.mu.Unlock()
+
+	metrics := &domain.ResourceMetrics{
+		VolumeTotal: usage.Total,
+		VolumeUsed:  usage.Used,
+		VolumeFree:  usage.Free,
+		UsedPercent: usage.UsedPercent,
+	}
+
+	first := samples[0]
+	if hours := now.Sub(first.at).Hours(); hours > 0 {
+		metrics.GrowthRate = (float64(usage.Used) - float64(first.used)) / hours
+	}
+
+	return metrics, nil
+}
+
+// listVolumes returns the usage of every mounted physical volume.
+func listVolumes() ([]domain.VolumeInfo, error) {
+	parts, err := disk.Partitions(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes []domain.VolumeInfo
+	for _, p := range parts {
+		usage, err := disk.Usage(p.Mountpoint)
+		if err != nil || usage.Total == 0 {
+			continue
+		}
+		volumes = append(volumes, domain.VolumeInfo{
+			Device:      p.Device,
+			Mountpoint:  p.Mountpoint,
+			Fstype:      p.Fstype,
+			Total:       usage.Total,
+			Used:        usage.Used,
+			Free:        usage.Free,
+			UsedPercent: usage.UsedPercent,
+		})
+	}
+	return volumes, nil
+}
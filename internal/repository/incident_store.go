@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"zenlight-support/internal/domain"
+)
+
+// IncidentStore appends incidents to a JSON lines file. An incident is
+// written once when it starts and again when it ends, the later line wins
+// on load. The file also records when each resource was first watched and
+// the runs of the app. A torn last line from a crash is ignored.
+type IncidentStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// IncidentLog is what the file holds once superseded lines are dropped.
+type IncidentLog struct {
+	Incidents []domain.Incident // in the order they started
+	Watched   map[string]int64  // when each resource was first watched, Unix ms
+	Sessions  []domain.Session  // in the order they started
+}
+
+// watchMark is the line recording when a resource was first watched, a zero
+// WatchedSince clears it.
+type watchMark struct {
+	ResourceID   string `json:"resourceId"`
+	WatchedSince int64  `json:"watchedSince"`
+}
+
+// sessionMark is the line recording a run of the app, written when it starts,
+// on every heartbeat and when it stops.
+type sessionMark struct {
+	Session domain.Session `json:"session"`
+}
+
+// record decodes any kind of line.
+type record struct {
+	domain.Incident
+	WatchedSince *int64          `json:"watchedSince"`
+	Session      *domain.Session `json:"session"`
+}
+
+func OpenIncidentStore(path string) *IncidentStore {
+	return &IncidentStore{path: path}
+}
+
+// Load reads the file, later lines of an entry replacing earlier ones.
+func (s *IncidentStore) Load() (*IncidentLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log := &IncidentLog{Watched: make(map[string]int64)}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return log, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var order, sessionOrder []string
+	byID := make(map[string]domain.Incident)
+	sessions := make(map[string]domain.Session)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		switch {
+		case rec.Session != nil && rec.Session.ID != "":
+			if _, ok := sessions[rec.Session.ID]; !ok {
+				sessionOrder = append(sessionOrder, rec.Session.ID)
+			}
+			sessions[rec.Session.ID] = *rec.Session
+		case rec.WatchedSince != nil && rec.ResourceID != "":
+			if *rec.WatchedSince > 0 {
+				log.Watched[rec.ResourceID] = *rec.WatchedSince
+			} else {
+				delete(log.Watched, rec.ResourceID)
+			}
+		case rec.ID != "":
+			if _, ok := byID[rec.ID]; !ok {
+				order = append(order, rec.ID)
+			}
+			byID[rec.ID] = rec.Incident
+		}
+	}
+
+	log.Incidents = make([]domain.Incident, 0, len(order))
+	for _, id := range order {
+		log.Incidents = append(log.Incidents, byID[id])
+	}
+	log.Sessions = make([]domain.Session, 0, len(sessionOrder))
+	for _, id := range sessionOrder {
+		log.Sessions = append(log.Sessions, sessions[id])
+	}
+	return log, nil
+}
+
+func (s *IncidentStore) Append(inc domain.Incident) error {
+	return s.write(inc)
+}
+
+// MarkWatched records when id was first watched, zero forgets it.
+func (s *IncidentStore) MarkWatched(id string, since int64) error {
+	return s.write(watchMark{ResourceID: id, WatchedSince: since})
+}
+
+// MarkSession records the start, last heartbeat or stop of a run.
+func (s *IncidentStore) MarkSession(session domain.Session) error {
+	return s.write(sessionMark{Session: session})
+}
+
+// Compact replaces the file with one line per entry of log, dropping the
+// superseded lines and whatever the caller left out of log.
+func (s *IncidentStore) Compact(log *IncidentLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, session := range log.Sessions {
+		if err := enc.Encode(sessionMark{Session: session}); err != nil {
+			return err
+		}
+	}
+	for id, since := range log.Watched {
+		if err := enc.Encode(watchMark{ResourceID: id, WatchedSince: since}); err != nil {
+			return err
+		}
+	}
+	for _, inc := range log.Incidents {
+		if err := enc.Encode(inc); err != nil {
+			return err
+		}
+	}
+
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to compact incident log: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *IncidentStore) write(v any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open incident log: %w", err)
+	}
+	defer f.Close()
+
+	// Start on a fresh line in case the previous write was torn
+	if _, err := f.Write(append(append([]byte{'\n'}, data...), '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
@@ -0,0 +1,532 @@
+package repository
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"zenlight-support/internal/domain"
+)
+
+// MetricsStore persists watcher samples in append-only segment files. Raw
+// samples go to one segment per UTC hour. Once older than the compaction age
+// a whole day of raw segments is merged into a single segment holding one
+// averaged sample per resource and minute. Segments past retention are deleted.
+//
+// Each record is framed as uvarint length, payload and CRC32, so a torn
+// write after a crash is detected and cut off when the segment is reopened.
+type MetricsStore struct {
+	dir string
+
+	mu           sync.Mutex
+	retention    time.Duration
+	compactAfter time.Duration
+	seg          *segmentWriter
+	hour         time.Time
+	prev         *segmentWriter // closed writer of the previous segment, reused if samples flip back
+
+	// files is held by queries while they list and read segments, and by
+	// maintenance while it swaps in a day segment or removes old ones, so a
+	// query never lists a segment that is gone by the time it is read
+	files sync.RWMutex
+}
+
+const (
+	rawPrefix     = "raw-"
+	dayPrefix     = "day-"
+	segmentExt    = ".seg"
+	rawLayout     = "2006010215"
+	dayLayout     = "20060102"
+	compactedStep = time.Minute
+
+	recordDefine = 1 // assigns a segment-local index to a resource ID
+	recordSample = 2
+
+	maxRecordSize = 4096
+	sampleValues  = 8
+)
+
+func OpenMetricsStore(dir string, retention, compactAfter time.Duration) (*MetricsStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+	return &MetricsStore{dir: dir, retention: retention, compactAfter: compactAfter}, nil
+}
+
+// Configure changes retention and compaction age, applied on the next Maintain.
+func (s *MetricsStore) Configure(retention, compactAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retention, s.compactAfter = retention, compactAfter
+}
+
+// Append writes one sample to the segment of the hour it was taken in.
+func (s *MetricsStore) Append(id string, sample domain.MetricSample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hour := time.UnixMilli(sample.At).UTC().Truncate(time.Hour)
+	if s.seg == nil || !hour.Equal(s.hour) {
+		path := filepath.Join(s.dir, rawPrefix+hour.Format(rawLayout)+segmentExt)
+		prev := s.prev
+		if s.seg != nil {
+			s.seg.close()
+			s.prev, s.seg = s.seg, nil
+		}
+		seg, err := reopenSegmentWriter(path, prev)
+		if err != nil {
+			return err
+		}
+		s.seg, s.hour = seg, hour
+	}
+	return s.seg.append(id, sample)
+}
+
+// Query returns the samples of id taken in [from, to), oldest first.
+func (s *MetricsStore) Query(id string, from, to int64) ([]domain.MetricSample, error) {
+	s.files.RLock()
+	defer s.files.RUnlock()
+
+	segments, err := s.segments()
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]bool)
+	for _, seg := range segments {
+		if seg.compacted {
+			days[seg.start.Format(dayLayout)] = true
+		}
+	}
+
+	var out []domain.MetricSample
+	for _, seg := range segments {
+		if seg.end.UnixMilli() <= from || seg.start.UnixMilli() >= to {
+			continue
+		}
+		// A crash during compaction can leave raw segments next to their day
+		if !seg.compacted && days[seg.start.Format(dayLayout)] {
+			continue
+		}
+		err := readSegment(seg.path, func(rid string, sample domain.MetricSample) {
+			if rid == id && sample.At >= from && sample.At < to {
+				out = append(out, sample)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	slices.SortStableFunc(out, func(a, b domain.MetricSample) int { return cmp.Compare(a.At, b.At) })
+	return out, nil
+}
+
+// Maintain flushes the open segment to disk, compacts old raw segments and
+// removes segments past retention. It is meant to run periodically.
+func (s *MetricsStore) Maintain(now time.Time) error {
+	s.mu.Lock()
+	if s.seg != nil {
+		if err := s.seg.file.Sync(); err != nil {
+			s.mu.Unlock()
+			return err
+		}
+	}
+	cutoff := now.Add(-s.retention)
+	compactBefore := now.Add(-s.compactAfter)
+	s.mu.Unlock()
+
+	segments, err := s.segments()
+	if err != nil {
+		return err
+	}
+
+	pending := make(map[time.Time][]segment)
+
+	var expired []segment
+	for _, seg := range segments {
+		if seg.end.Before(cutoff) {
+			expired = append(expired, seg)
+			continue
+		}
+		if !seg.compacted {
+			day := seg.start.Truncate(24 * time.Hour)
+			if day.Add(24 * time.Hour).Before(compactBefore) {
+				pending[day] = append(pending[day], seg)
+			}
+		}
+	}
+
+	if err := s.remove(expired); err != nil {
+		return err
+	}
+	for day, raws := range pending {
+		if err := s.compact(day, raws); err != nil {
+			return fmt.Errorf("failed to compact metrics of %s: %w", day.Format(time.DateOnly), err)
+		}
+	}
+	return nil
+}
+
+func (s *MetricsStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seg == nil {
+		return nil
+	}
+	err := s.seg.close()
+	s.seg = nil
+	return err
+}
+
+// compact averages a day of raw samples per resource and minute into a day
+// segment, merged with any earlier compaction of that day. The day segment is
+// written next to the raws and renamed into place before they are removed.
+func (s *MetricsStore) compact(day time.Time, raws []segment) error {
+	type bucket struct {
+		id     string
+		at     int64
+		n      int
+		status domain.Status
+		sums   [sampleValues]float64
+		kept   bool // taken from an earlier compaction of the day
+	}
+	buckets := make(map[string]*bucket)
+
+	// A crash while the raws were being removed leaves a day segment and
+	// some of its raws behind. Minutes already compacted are kept as they
+	// are, the leftover raws only fill in minutes the day does not have.
+	path := filepath.Join(s.dir, dayPrefix+day.Format(dayLayout)+segmentExt)
+	err := readSegment(path, func(id string, sample domain.MetricSample) {
+		b := &bucket{id: id, at: sample.At, n: 1, status: sample.Status, kept: true}
+		for i, v := range sampleFields(&sample) {
+			b.sums[i] = *v
+		}
+		buckets[fmt.Sprintf("%s/%d", id, sample.At)] = b
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	for _, seg := range raws {
+		err := readSegment(seg.path, func(id string, sample domain.MetricSample) {
+			at := time.UnixMilli(sample.At).Truncate(compactedStep).UnixMilli()
+			key := fmt.Sprintf("%s/%d", id, at)
+			b, ok := buckets[key]
+			if !ok {
+				b = &bucket{id: id, at: at}
+				buckets[key] = b
+			}
+			if b.kept {
+				return
+			}
+			b.n++
+			b.status = sample.Status
+			for i, v := range sampleFields(&sample) {
+				b.sums[i] += *v
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	sorted := make([]*bucket, 0, len(buckets))
+	for _, b := range buckets {
+		sorted = append(sorted, b)
+	}
+	slices.SortFunc(sorted, func(a, b *bucket) int {
+		return cmp.Or(cmp.Compare(a.at, b.at), strings.Compare(a.id, b.id))
+	})
+
+	tmp := path + ".tmp"
+	w, err := createSegmentWriter(tmp)
+	if err != nil {
+		return err
+	}
+	for _, b := range sorted {
+		sample := domain.MetricSample{At: b.at, Status: b.status}
+		for i, v := range sampleFields(&sample) {
+			*v = b.sums[i] / float64(b.n)
+		}
+		if err := w.append(b.id, sample); err != nil {
+			w.close()
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err := w.file.Sync(); err != nil {
+		w.close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := w.close(); err != nil {
+		return err
+	}
+
+	s.files.Lock()
+	defer s.files.Unlock()
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, seg := range raws {
+		if s.seg != nil && s.seg.path == seg.path {
+			continue
+		}
+		if err := os.Remove(seg.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MetricsStore) remove(segments []segment) error {
+	s.files.Lock()
+	defer s.files.Unlock()
+	for _, seg := range segments {
+		if err := os.Remove(seg.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+type segment struct {
+	path       string
+	start, end time.Time
+	compacted  bool
+}
+
+func (s *MetricsStore) segments() ([]segment, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []segment
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		stamp := strings.TrimSuffix(name, segmentExt)
+
+		seg := segment{path: filepath.Join(s.dir, name)}
+		switch {
+		case strings.HasPrefix(stamp, rawPrefix):
+			t, err := time.Parse(rawLayout, strings.TrimPrefix(stamp, rawPrefix))
+			if err != nil {
+				continue
+			}
+			seg.start, seg.end = t, t.Add(time.Hour)
+		case strings.HasPrefix(stamp, dayPrefix):
+			t, err := time.Parse(dayLayout, strings.TrimPrefix(stamp, dayPrefix))
+			if err != nil {
+				continue
+			}
+			seg.start, seg.end, seg.compacted = t, t.Add(24*time.Hour), true
+		default:
+			continue
+		}
+		out = append(out, seg)
+	}
+
+	slices.SortFunc(out, func(a, b segment) int { return a.start.Compare(b.start) })
+	return out, nil
+}
+
+type segmentWriter struct {
+	path  string
+	file  *os.File
+	index map[string]uint64
+	size  int64 // offset just past the last record written
+}
+
+func createSegmentWriter(path string) (*segmentWriter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &segmentWriter{path: path, file: file, index: make(map[string]uint64)}, nil
+}
+
+// reopenSegmentWriter continues a segment closed earlier by prev without
+// rescanning it, as long as nothing else changed the file since. Samples of
+// concurrent probes can straddle an hour and switch segments back and forth.
+func reopenSegmentWriter(path string, prev *segmentWriter) (*segmentWriter, error) {
+	if prev == nil || prev.path != path {
+		return openSegmentWriter(path)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != prev.size {
+		return openSegmentWriter(path)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(prev.size, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &segmentWriter{path: path, file: file, index: prev.index, size: prev.size}, nil
+}
+
+// openSegmentWriter reopens an existing segment for appending. Its ID table is
+// rebuilt and anything after the last intact record is truncated.
+func openSegmentWriter(path string) (*segmentWriter, error) {
+	w := &segmentWriter{path: path, index: make(map[string]uint64)}
+
+	valid, err := scanSegment(path, func(kind byte, payload []byte) {
+		if kind == recordDefine {
+			idx, n := binary.Uvarint(payload)
+			if n > 0 {
+				w.index[string(payload[n:])] = idx
+			}
+		}
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Truncate(valid); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	w.file = file
+	w.size = valid
+	return w, nil
+}
+
+func (w *segmentWriter) append(id string, sample domain.MetricSample) error {
+	idx, ok := w.index[id]
+	if !ok {
+		idx = uint64(len(w.index))
+		payload := binary.AppendUvarint([]byte{recordDefine}, idx)
+		payload = append(payload, id...)
+		if err := w.write(payload); err != nil {
+			return err
+		}
+		w.index[id] = idx
+	}
+
+	payload := binary.AppendUvarint([]byte{recordSample}, idx)
+	payload = binary.AppendVarint(payload, sample.At)
+	payload = binary.AppendUvarint(payload, uint64(sample.Status))
+	for _, v := range sampleFields(&sample) {
+		payload = binary.LittleEndian.AppendUint32(payload, math.Float32bits(float32(*v)))
+	}
+	return w.write(payload)
+}
+
+// write emits one frame in a single call so a crash leaves at most one torn record.
+func (w *segmentWriter) write(payload []byte) error {
+	frame := binary.AppendUvarint(nil, uint64(len(payload)))
+	frame = append(frame, payload...)
+	frame = binary.LittleEndian.AppendUint32(frame, crc32.ChecksumIEEE(payload))
+	n, err := w.file.Write(frame)
+	w.size += int64(n)
+	return err
+}
+
+func (w *segmentWriter) close() error {
+	return w.file.Close()
+}
+
+func readSegment(path string, fn func(id string, sample domain.MetricSample)) error {
+	ids := make(map[uint64]string)
+	_, err := scanSegment(path, func(kind byte, payload []byte) {
+		idx, n := binary.Uvarint(payload)
+		if n <= 0 {
+			return
+		}
+		payload = payload[n:]
+
+		switch kind {
+		case recordDefine:
+			ids[idx] = string(payload)
+		case recordSample:
+			id, ok := ids[idx]
+			if !ok {
+				return
+			}
+			at, n := binary.Varint(payload)
+			if n <= 0 {
+				return
+			}
+			payload = payload[n:]
+			status, n := binary.Uvarint(payload)
+			if n <= 0 || len(payload[n:]) < 4*sampleValues {
+				return
+			}
+			payload = payload[n:]
+
+			sample := domain.MetricSample{At: at, Status: domain.Status(status)}
+			for i, v := range sampleFields(&sample) {
+				*v = float64(math.Float32frombits(binary.LittleEndian.Uint32(payload[4*i:])))
+			}
+			fn(id, sample)
+		}
+	})
+	return err
+}
+
+// scanSegment calls fn for every intact record and returns the offset just
+// past the last one. A torn or corrupt record ends the scan without an error.
+func scanSegment(path string, fn func(kind byte, payload []byte)) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	var valid int64
+	for {
+		size, err := binary.ReadUvarint(r)
+		if err != nil || size == 0 || size > maxRecordSize {
+			return valid, nil
+		}
+		buf := make([]byte, size+4)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return valid, nil
+		}
+		payload := buf[:size]
+		if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(buf[size:]) {
+			return valid, nil
+		}
+
+		fn(payload[0], payload[1:])
+		valid += int64(uvarintLen(size)) + int64(size) + 4
+	}
+}
+
+func uvarintLen(v uint64) int {
+	return len(binary.AppendUvarint(nil, v))
+}
+
+func sampleFields(s *domain.MetricSample) [sampleValues]*float64 {
+	return [sampleValues]*float64{&s.CPUUsage, &s.MemUsage, &s.TreeCPU, &s.TreeMem, &s.ReadRate, &s.WriteRate, &s.UsedPercent, &s.Latency}
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"zenlight-support/internal/domain"
+)
+
+// Delivery is one notification waiting to go out on one channel.
+type Delivery struct {
+	ID           string              `json:"id"`
+	Channel      string              `json:"channel"`
+	Notification domain.Notification `json:"notification"`
+	Attempts     int                 `json:"attempts"`
+	NextAt       int64               `json:"nextAt"` // Unix ms
+	LastError    string              `json:"lastError,omitempty"`
+	CreatedAt    int64               `json:"createdAt"`
+}
+
+// Outbox keeps pending deliveries as one JSON file each, so they survive
+// restarts until a channel accepts them. Files are replaced atomically.
+type Outbox struct {
+	dir string
+	mu  sync.Mutex
+}
+
+const outboxExt = ".json"
+
+func OpenOutbox(dir string) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+	return &Outbox{dir: dir}, nil
+}
+
+func (o *Outbox) Put(d Delivery) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	path := o.path(d.ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (o *Outbox) Remove(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := os.Remove(o.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Pending returns every stored delivery, oldest first. Unreadable files are
+// skipped so one bad entry does not block the rest.
+func (o *Outbox) Pending() ([]Delivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Delivery
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), outboxExt) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(o.dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var d Delivery
+		if err := json.Unmarshal(data, &d); err != nil {
+			continue
+		}
+		out = append(out, d)
+	}
+
+	slices.SortFunc(out, func(a, b Delivery) int { return cmp.Compare(a.CreatedAt, b.CreatedAt) })
+	return out, nil
+}
+
+func (o *Outbox) path(id string) string {
+	return filepath.Join(o.dir, id+outboxExt)
+}
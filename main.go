@@ -74,6 +74,7 @@ func main() {
 		DisableResize:    false,
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        a.Startup,
+		OnDomReady:       a.DomReady,
 		OnShutdown:       a.Shutdown,
 		Bind: []interface{}{
 			a,
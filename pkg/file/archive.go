@@ -0,0 +1,184 @@
+package file
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+const (
+	MaxEntrySize   = 512 << 20 // largest single file an archive may unpack to
+	MaxArchiveSize = 2 << 30   // largest total an archive may unpack to
+	MaxEntries     = 50000
+)
+
+// IsArchive reports whether name is a package Extract can unpack.
+func IsArchive(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".zip") || strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// Extract stages the regular files of a .zip or .tar.gz package, keeping its
+// folder structure. stripPrefix is removed from entry names and entries
+// outside it are skipped. Entries that would land outside the target,
+// symlinks, other special files and oversized entries fail the whole
+// archive, and so does a package without any file to stage. It returns the
+// number of files staged.
+func (s *Staged) Extract(name string, data []byte, stripPrefix string) (int, error) {
+	x := &extractor{stage: s, prefix: strings.Trim(normalizeEntry(stripPrefix), "/")}
+	if x.prefix == "." {
+		x.prefix = ""
+	}
+
+	var err error
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		err = x.zip(data)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		err = x.tarGz(data)
+	default:
+		return 0, fmt.Errorf("unsupported archive: %s", name)
+	}
+	if err == nil && x.count == 0 {
+		if x.prefix != "" {
+			return 0, fmt.Errorf("no files under %s/ in %s", x.prefix, name)
+		}
+		return 0, fmt.Errorf("no files in %s", name)
+	}
+	return x.count, err
+}
+
+type extractor struct {
+	stage  *Staged
+	prefix string
+	count  int
+	total  int64
+}
+
+func (x *extractor) zip(data []byte) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("invalid zip archive: %w", err)
+	}
+
+	for _, f := range zr.File {
+		mode := f.Mode()
+		if mode.IsDir() {
+			continue
+		}
+		if !mode.IsRegular() {
+			return fmt.Errorf("entry %s is not a regular file", f.Name)
+		}
+		if f.UncompressedSize64 > MaxEntrySize {
+			return fmt.Errorf("entry %s is larger than %d bytes", f.Name, MaxEntrySize)
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read entry %s: %w", f.Name, err)
+		}
+		err = x.entry(f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *extractor) tarGz(data []byte) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid gzip stream: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid tar archive: %w", err)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir, tar.TypeXGlobalHeader, tar.TypeXHeader, tar.TypeGNULongName, tar.TypeGNULongLink:
+			// Folders are created for the files in them, the rest is metadata
+			// such as the pax header git archive starts with
+			continue
+		case tar.TypeReg:
+		case tar.TypeSymlink, tar.TypeLink:
+			return fmt.Errorf("entry %s is a link", hdr.Name)
+		default:
+			return fmt.Errorf("entry %s is not a regular file", hdr.Name)
+		}
+		if hdr.Size > MaxEntrySize {
+			return fmt.Errorf("entry %s is larger than %d bytes", hdr.Name, MaxEntrySize)
+		}
+
+		if err := x.entry(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// entry stages one file. Declared sizes are checked by the callers, the copy
+// limits guard against archives that lie about them.
+func (x *extractor) entry(name string, r io.Reader) error {
+	rel, ok := x.strip(name)
+	if !ok {
+		return nil
+	}
+	if _, err := localPath(rel); err != nil || hasDotDot(name) {
+		return fmt.Errorf("entry %s points outside the target directory", name)
+	}
+
+	x.count++
+	if x.count > MaxEntries {
+		return fmt.Errorf("archive has more than %d files", MaxEntries)
+	}
+
+	limit := min(MaxEntrySize, MaxArchiveSize-x.total)
+	n, err := x.stage.copy(rel, r, 0755, limit)
+	x.total += n
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", name, err)
+	}
+	return nil
+}
+
+// strip removes the prefix folder from name, reporting false for entries
+// outside of it.
+func (x *extractor) strip(name string) (string, bool) {
+	name = normalizeEntry(name)
+	if x.prefix == "" {
+		return name, true
+	}
+	rest, ok := strings.CutPrefix(name, x.prefix+"/")
+	return rest, ok && rest != ""
+}
+
+// normalizeEntry turns Windows separators written by some zip tools into
+// slashes and drops the leading "./" of archives made with "tar czf x.tgz .".
+func normalizeEntry(name string) string {
+	name = strings.ReplaceAll(name, `\`, "/")
+	for strings.HasPrefix(name, "./") {
+		name = strings.TrimLeft(name[2:], "/")
+	}
+	return name
+}
+
+// hasDotDot rejects parent references even when they would resolve inside
+// the target, no legitimate package needs them.
+func hasDotDot(name string) bool {
+	return slices.Contains(strings.Split(normalizeEntry(name), "/"), "..")
+}
@@ -0,0 +1,273 @@
+package file
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type entry struct {
+	name string
+	body string
+	link string // makes the entry a symlink to link
+	size int64  // declared size, overrides len(body)
+}
+
+func zipOf(t *testing.T, entries ...entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		switch {
+		case e.link != "":
+			h := &zip.FileHeader{Name: e.name}
+			h.SetMode(os.ModeSymlink | 0777)
+			w, err := zw.CreateHeader(h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write([]byte(e.link))
+		case e.size > 0:
+			w, err := zw.CreateRaw(&zip.FileHeader{Name: e.name, Method: zip.Store, CompressedSize64: uint64(len(e.body)), UncompressedSize64: uint64(e.size)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write([]byte(e.body))
+		default:
+			w, err := zw.Create(e.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write([]byte(e.body))
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarGzOf(t *testing.T, entries ...entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.body))}
+		switch {
+		case e.link != "":
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeSymlink, e.link, 0
+		case strings.HasSuffix(e.name, "/"):
+			hdr.Typeflag, hdr.Size = tar.TypeDir, 0
+		case e.size > 0:
+			// Only the header is written, the entry is rejected before its body is read
+			hdr.Size = e.size
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if e.size == 0 {
+			tw.Write([]byte(e.body))
+		}
+	}
+	tw.Flush()
+	gz.Close()
+	return buf.Bytes()
+}
+
+// gitArchiveOf starts with the pax global header git archive writes.
+func gitArchiveOf(t *testing.T, entries ...entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Typeflag: tar.TypeXGlobalHeader, Name: "pax_global_header", PAXRecords: map[string]string{"comment": "0123abcd"}})
+	for _, e := range entries {
+		tw.WriteHeader(&tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.body))})
+		tw.Write([]byte(e.body))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive string
+		data    func(t *testing.T) []byte
+		prefix  string
+		want    []string // files expected in the target
+		wantErr string
+	}{
+		{
+			name:    "zip keeps folders",
+			archive: "pkg.zip",
+			data: func(t *testing.T) []byte {
+				return zipOf(t, entry{name: "bin/app.dll", body: "a"}, entry{name: "wwwroot/index.html", body: "i"})
+			},
+			want: []string{"bin/app.dll", "wwwroot/index.html"},
+		},
+		{
+			name:    "zip with windows separators",
+			archive: "pkg.zip",
+			data:    func(t *testing.T) []byte { return zipOf(t, entry{name: `bin\app.dll`, body: "a"}) },
+			want:    []string{"bin/app.dll"},
+		},
+		{
+			name:    "strip prefix skips entries outside it",
+			archive: "pkg.zip",
+			data: func(t *testing.T) []byte {
+				return zipOf(t, entry{name: "release/bin/app.dll", body: "a"}, entry{name: "README.md", body: "r"})
+			},
+			prefix: "release/",
+			want:   []string{"bin/app.dll"},
+		},
+		{
+			name:    "strip prefix on dot slash names",
+			archive: "pkg.tgz",
+			data: func(t *testing.T) []byte {
+				return tarGzOf(t, entry{name: "./"}, entry{name: "./app/"}, entry{name: "./app/bin/x", body: "x"})
+			},
+			prefix: "app",
+			want:   []string{"bin/x"},
+		},
+		{
+			name:    "strip prefix matching nothing",
+			archive: "pkg.zip",
+			data:    func(t *testing.T) []byte { return zipOf(t, entry{name: "bin/app.dll", body: "a"}) },
+			prefix:  "release",
+			wantErr: "no files under release/",
+		},
+		{
+			name:    "git archive pax header",
+			archive: "pkg.tar.gz",
+			data:    func(t *testing.T) []byte { return gitArchiveOf(t, entry{name: "app/bin/x", body: "x"}) },
+			want:    []string{"app/bin/x"},
+		},
+		{
+			name:    "zip parent traversal",
+			archive: "evil.zip",
+			data:    func(t *testing.T) []byte { return zipOf(t, entry{name: "../../evil.dll", body: "x"}) },
+			wantErr: "outside the target",
+		},
+		{
+			name:    "traversal resolving inside",
+			archive: "evil.zip",
+			data:    func(t *testing.T) []byte { return zipOf(t, entry{name: "bin/../app.dll", body: "x"}) },
+			wantErr: "outside the target",
+		},
+		{
+			name:    "tar parent traversal",
+			archive: "evil.tgz",
+			data:    func(t *testing.T) []byte { return tarGzOf(t, entry{name: "../evil.dll", body: "x"}) },
+			wantErr: "outside the target",
+		},
+		{
+			name:    "zip absolute path",
+			archive: "evil.zip",
+			data:    func(t *testing.T) []byte { return zipOf(t, entry{name: "/etc/cron.d/evil", body: "x"}) },
+			wantErr: "outside the target",
+		},
+		{
+			name:    "tar absolute path",
+			archive: "evil.tar.gz",
+			data:    func(t *testing.T) []byte { return tarGzOf(t, entry{name: "/etc/cron.d/evil", body: "x"}) },
+			wantErr: "outside the target",
+		},
+		{
+			name:    "zip symlink",
+			archive: "evil.zip",
+			data:    func(t *testing.T) []byte { return zipOf(t, entry{name: "bin", link: "/etc"}) },
+			wantErr: "not a regular file",
+		},
+		{
+			name:    "tar symlink",
+			archive: "evil.tgz",
+			data:    func(t *testing.T) []byte { return tarGzOf(t, entry{name: "bin", link: "/etc"}) },
+			wantErr: "is a link",
+		},
+		{
+			name:    "zip oversized entry",
+			archive: "big.zip",
+			data:    func(t *testing.T) []byte { return zipOf(t, entry{name: "big.bin", body: "x", size: MaxEntrySize + 1}) },
+			wantErr: "larger than",
+		},
+		{
+			name:    "tar oversized entry",
+			archive: "big.tgz",
+			data:    func(t *testing.T) []byte { return tarGzOf(t, entry{name: "big.bin", size: MaxEntrySize + 1}) },
+			wantErr: "larger than",
+		},
+		{
+			name:    "empty package",
+			archive: "empty.zip",
+			data:    func(t *testing.T) []byte { return zipOf(t) },
+			wantErr: "no files",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			target := filepath.Join(root, "svc")
+			s, err := Stage(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Cleanup()
+
+			n, err := s.Extract(tt.archive, tt.data(t), tt.prefix)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Extract() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if n != len(tt.want) {
+				t.Errorf("Extract() = %d files, want %d", n, len(tt.want))
+			}
+
+			if err := s.Commit(); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			filepath.WalkDir(target, func(path string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					rel, _ := filepath.Rel(target, path)
+					got = append(got, filepath.ToSlash(rel))
+				}
+				return nil
+			})
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("target holds %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyLimit(t *testing.T) {
+	s, err := Stage(filepath.Join(t.TempDir(), "svc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Cleanup()
+
+	// Entries that declare a small size but unpack to more are cut off
+	if _, err := s.copy("bomb.bin", strings.NewReader("0123456789"), 0644, 4); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Fatalf("copy() error = %v, want size error", err)
+	}
+	if _, err := s.copy("ok.bin", strings.NewReader("0123"), 0644, 4); err != nil {
+		t.Fatalf("copy() error = %v", err)
+	}
+}
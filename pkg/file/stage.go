@@ -0,0 +1,192 @@
+package file
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Staged collects files in a temporary folder next to the target so they can
+// be swapped in together. Files they replace are moved to a backup folder and
+// put back by Rollback.
+type Staged struct {
+	target  string
+	staging string
+	backup  string
+
+	names    []string // paths relative to target, in write order
+	added    []string // files that did not exist before Commit
+	replaced []string // files moved to backup by Commit
+	created  []string // folders Commit had to create in target
+}
+
+// Stage prepares an install into target, creating it when missing. Staging
+// and backup folders sit beside target so the swap is a rename on the same
+// volume.
+func Stage(target string) (*Staged, error) {
+	target = filepath.Clean(target)
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create target directory: %w", err)
+	}
+
+	parent, base := filepath.Dir(target), filepath.Base(target)
+	staging, err := os.MkdirTemp(parent, "."+base+".staging-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	backup, err := os.MkdirTemp(parent, "."+base+".backup-")
+	if err != nil {
+		os.RemoveAll(staging)
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	return &Staged{target: target, staging: staging, backup: backup}, nil
+}
+
+// Write stages one file. name is relative to the target and may not leave it.
+func (s *Staged) Write(name string, data []byte, perm os.FileMode) error {
+	_, err := s.copy(name, bytes.NewReader(data), perm, int64(len(data)))
+	return err
+}
+
+// copy stages up to limit bytes from r as name and fails if r holds more.
+func (s *Staged) copy(name string, r io.Reader, perm os.FileMode, limit int64) (int64, error) {
+	rel, err := localPath(name)
+	if err != nil {
+		return 0, err
+	}
+
+	path := filepath.Join(s.staging, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, io.LimitReader(r, limit+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, err
+	}
+	if n > limit {
+		return n, fmt.Errorf("%s is larger than %d bytes", name, limit)
+	}
+
+	if !slices.Contains(s.names, rel) {
+		s.names = append(s.names, rel)
+	}
+	return n, nil
+}
+
+// ErrRollback marks a Commit failure whose own rollback failed as well, the
+// target then holds a mix of old and new files.
+var ErrRollback = errors.New("rollback failed")
+
+// Commit moves the files each staged file replaces into the backup folder and
+// the staged files into the target. A failure part way restores the target
+// before returning, or wraps ErrRollback when that fails too.
+func (s *Staged) Commit() error {
+	for _, rel := range s.names {
+		if err := s.swap(rel); err != nil {
+			if rbErr := s.Rollback(); rbErr != nil {
+				return errors.Join(err, fmt.Errorf("%w: %w", ErrRollback, rbErr))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Staged) swap(rel string) error {
+	dst := filepath.Join(s.target, rel)
+
+	info, err := os.Lstat(dst)
+	switch {
+	case err == nil && info.IsDir():
+		return fmt.Errorf("cannot replace directory %s with a file", rel)
+	case err == nil:
+		saved := filepath.Join(s.backup, rel)
+		if err := os.MkdirAll(filepath.Dir(saved), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(dst, saved); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", rel, err)
+		}
+		s.replaced = append(s.replaced, rel)
+	case os.IsNotExist(err):
+		if err := s.mkdirs(filepath.Dir(dst)); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	if err := os.Rename(filepath.Join(s.staging, rel), dst); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", rel, err)
+	}
+	if !slices.Contains(s.replaced, rel) {
+		s.added = append(s.added, rel)
+	}
+	return nil
+}
+
+// mkdirs creates dir and remembers which of its parents did not exist yet.
+func (s *Staged) mkdirs(dir string) error {
+	var missing []string
+	for d := dir; d != s.target; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, d := range slices.Backward(missing) {
+		s.created = append(s.created, d)
+	}
+	return nil
+}
+
+// Rollback removes the files Commit added and moves the backed up ones back.
+func (s *Staged) Rollback() error {
+	var errs []error
+	for _, rel := range slices.Backward(s.added) {
+		if err := os.Remove(filepath.Join(s.target, rel)); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	for _, rel := range slices.Backward(s.replaced) {
+		if err := os.Rename(filepath.Join(s.backup, rel), filepath.Join(s.target, rel)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", rel, err))
+		}
+	}
+	for _, d := range slices.Backward(s.created) {
+		// Only empty folders go, anything else was put there by someone else
+		os.Remove(d)
+	}
+
+	s.added, s.replaced, s.created = nil, nil, nil
+	return errors.Join(errs...)
+}
+
+// Cleanup deletes the staging and backup folders. After a successful install
+// it drops the backup, so call it only once Rollback is no longer needed.
+func (s *Staged) Cleanup() error {
+	return errors.Join(os.RemoveAll(s.staging), os.RemoveAll(s.backup))
+}
+
+func localPath(name string) (string, error) {
+	rel := filepath.Clean(filepath.FromSlash(name))
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("invalid file name: %s", name)
+	}
+	return rel, nil
+}
@@ -0,0 +1,143 @@
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Kind string
+
+const (
+	TCP  Kind = "tcp"
+	HTTP Kind = "http"
+)
+
+const (
+	defaultTimeout = 5 * time.Second
+	maxBodyBytes   = 1 << 20
+)
+
+// Probe describes a TCP connect or HTTP(S) request check.
+type Probe struct {
+	Kind           Kind   `json:"kind" yaml:"kind"`
+	Target         string `json:"target" yaml:"target"` // host:port for tcp, URL for http
+	ExpectStatus   int    `json:"expectStatus,omitempty" yaml:"expect_status,omitempty"`
+	BodyContains   string `json:"bodyContains,omitempty" yaml:"body_contains,omitempty"`
+	TimeoutSeconds int    `json:"timeoutSeconds,omitempty" yaml:"timeout_seconds,omitempty"`
+	SkipTLSVerify  bool   `json:"skipTlsVerify,omitempty" yaml:"skip_tls_verify,omitempty"`
+}
+
+type Result struct {
+	Up         bool   `json:"up"`
+	Latency    int64  `json:"latency"` // ms
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	CheckedAt  int64  `json:"checkedAt"` // Unix ms
+}
+
+func (p Probe) Validate() error {
+	switch p.Kind {
+	case TCP:
+		if _, _, err := net.SplitHostPort(p.Target); err != nil {
+			return fmt.Errorf("invalid tcp target %q: %w", p.Target, err)
+		}
+	case HTTP:
+		if !strings.HasPrefix(p.Target, "http://") && !strings.HasPrefix(p.Target, "https://") {
+			return fmt.Errorf("invalid http target %q: must start with http:// or https://", p.Target)
+		}
+	default:
+		return fmt.Errorf("unsupported probe kind: %q", p.Kind)
+	}
+	return nil
+}
+
+func (p Probe) timeout() time.Duration {
+	if p.TimeoutSeconds > 0 {
+		return time.Duration(p.TimeoutSeconds) * time.Second
+	}
+	return defaultTimeout
+}
+
+// Run executes the probe once. Failures are reported in the result, never as a panic or error.
+func Run(ctx context.Context, p Probe) Result {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout())
+	defer cancel()
+
+	start := time.Now()
+	var res Result
+	var err error
+
+	if err = p.Validate(); err == nil {
+		switch p.Kind {
+		case TCP:
+			err = runTCP(ctx, p)
+		case HTTP:
+			res.StatusCode, err = runHTTP(ctx, p)
+		}
+	}
+
+	res.Latency = time.Since(start).Milliseconds()
+	res.CheckedAt = start.UnixMilli()
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Up = true
+	return res
+}
+
+func runTCP(ctx context.Context, p Probe) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", p.Target)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func runHTTP(ctx context.Context, p Probe) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Target, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			DisableKeepAlives: true,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: p.SkipTLSVerify},
+		},
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if p.ExpectStatus != 0 {
+		if resp.StatusCode != p.ExpectStatus {
+			return resp.StatusCode, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+	} else if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if p.BodyContains != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+		if err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to read body: %w", err)
+		}
+		if !strings.Contains(string(body), p.BodyContains) {
+			return resp.StatusCode, fmt.Errorf("body does not contain %q", p.BodyContains)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
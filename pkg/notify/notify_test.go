@@ -0,0 +1,196 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWebhookSend(t *testing.T) {
+	var gotBody, gotAuth, gotType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody, gotAuth, gotType = string(b), r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+	}))
+	defer srv.Close()
+
+	w := Webhook{
+		URL:      srv.URL,
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		Template: `{"text": {{json .Title}}}`,
+	}
+	if err := w.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Send(context.Background(), Message{Data: map[string]string{"Title": `disk "C:" full`}}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if want := `{"text": "disk \"C:\" full"}`; gotBody != want {
+		t.Errorf("body = %s, want %s", gotBody, want)
+	}
+	if gotAuth != "Bearer token" || gotType != "application/json" {
+		t.Errorf("headers = %q, %q", gotAuth, gotType)
+	}
+
+	// Without a template the data is sent as JSON
+	w.Template = ""
+	if err := w.Send(context.Background(), Message{Data: map[string]int{"n": 1}}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if want := `{"n":1}`; gotBody != want {
+		t.Errorf("body = %s, want %s", gotBody, want)
+	}
+}
+
+func TestWebhookStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	err := Webhook{URL: srv.URL}.Send(context.Background(), Message{})
+	if err == nil || !strings.Contains(err.Error(), "status 502") {
+		t.Fatalf("Send() error = %v, want status error", err)
+	}
+}
+
+func TestWebhookValidate(t *testing.T) {
+	for _, w := range []Webhook{
+		{URL: "hooks.example.com/x"},
+		{URL: "ftp://hooks.example.com/x"},
+		{URL: "https://hooks.example.com/x", Template: "{{.Title"},
+	} {
+		if err := w.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded", w)
+		}
+	}
+}
+
+type mail struct {
+	from string
+	to   []string
+	data string
+}
+
+// smtpServer is a minimal SMTP stand-in that accepts one message and hands
+// the envelope and data back on the returned channel.
+func smtpServer(t *testing.T, rejectRcpt bool) (string, int, <-chan mail) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	out := make(chan mail, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+
+		var m mail
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			cmd := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				m.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				if rejectRcpt {
+					reply("550 No such user")
+					continue
+				}
+				m.to = append(m.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var b strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					b.WriteString(l)
+				}
+				m.data = b.String()
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 Bye")
+				out <- m
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return host, p, out
+}
+
+func TestSMTPSend(t *testing.T) {
+	host, port, got := smtpServer(t, false)
+	s := SMTP{
+		Host:            host,
+		Port:            port,
+		From:            "alerts@example.com",
+		To:              []string{"ops@example.com", "helpdesk@example.com"},
+		SubjectTemplate: "[{{.Severity}}] {{.Title}}",
+		TimeoutSeconds:  5,
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	msg := Message{
+		Subject: "ignored",
+		Text:    "line one\nline two",
+		Data:    map[string]string{"Severity": "critical", "Title": "Report Service down"},
+	}
+	if err := s.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	m := <-got
+	if m.from != s.From || strings.Join(m.to, ",") != "ops@example.com,helpdesk@example.com" {
+		t.Errorf("envelope = %s -> %v", m.from, m.to)
+	}
+	for _, want := range []string{
+		"Subject: [critical] Report Service down\r\n",
+		"To: ops@example.com, helpdesk@example.com\r\n",
+		"\r\n\r\nline one\r\nline two",
+	} {
+		if !strings.Contains(m.data, want) {
+			t.Errorf("mail is missing %q:\n%s", want, m.data)
+		}
+	}
+}
+
+func TestSMTPRejectedRecipient(t *testing.T) {
+	host, port, _ := smtpServer(t, true)
+	s := SMTP{Host: host, Port: port, From: "alerts@example.com", To: []string{"nobody@example.com"}, TimeoutSeconds: 5}
+	if err := s.Send(context.Background(), Message{Subject: "x", Text: "y"}); err == nil || !strings.Contains(err.Error(), "550") {
+		t.Fatalf("Send() error = %v, want the 550 reply", err)
+	}
+}
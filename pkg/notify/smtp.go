@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP sends a plain text email. Port 465 uses implicit TLS, other ports
+// upgrade with STARTTLS when the server offers it.
+type SMTP struct {
+	Host            string   `json:"host" yaml:"host"`
+	Port            int      `json:"port,omitempty" yaml:"port,omitempty"` // default 25
+	Username        string   `json:"username,omitempty" yaml:"username,omitempty"`
+	Password        string   `json:"password,omitempty" yaml:"password,omitempty"`
+	From            string   `json:"from" yaml:"from"`
+	To              []string `json:"to" yaml:"to"`
+	SubjectTemplate string   `json:"subjectTemplate,omitempty" yaml:"subject_template,omitempty"`
+	BodyTemplate    string   `json:"bodyTemplate,omitempty" yaml:"body_template,omitempty"`
+	SkipTLSVerify   bool     `json:"skipTlsVerify,omitempty" yaml:"skip_tls_verify,omitempty"`
+	TimeoutSeconds  int      `json:"timeoutSeconds,omitempty" yaml:"timeout_seconds,omitempty"`
+}
+
+func (s SMTP) Validate() error {
+	if s.Host == "" || s.From == "" || len(s.To) == 0 {
+		return fmt.Errorf("smtp channel needs a host, a from address and at least one recipient")
+	}
+	for _, tmpl := range []string{s.SubjectTemplate, s.BodyTemplate} {
+		if tmpl == "" {
+			continue
+		}
+		if _, err := parse("smtp", tmpl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s SMTP) Send(ctx context.Context, msg Message) error {
+	subject, body := msg.Subject, msg.Text
+	var err error
+	if s.SubjectTemplate != "" {
+		if subject, err = render("subject", s.SubjectTemplate, msg.Data); err != nil {
+			return err
+		}
+	}
+	if s.BodyTemplate != "" {
+		if body, err = render("body", s.BodyTemplate, msg.Data); err != nil {
+			return err
+		}
+	}
+
+	port := s.Port
+	if port == 0 {
+		port = 25
+	}
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: s.Host, InsecureSkipVerify: s.SkipTLSVerify}
+
+	ctx, cancel := context.WithTimeout(ctx, timeoutOr(s.TimeoutSeconds))
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if port == 465 {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && port != 465 {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if s.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(s.From); err != nil {
+		return err
+	}
+	for _, to := range s.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(buildMail(s.From, s.To, subject, body)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func buildMail(from string, to []string, subject, body string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", strings.ReplaceAll(strings.ReplaceAll(subject, "\r", ""), "\n", " "))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
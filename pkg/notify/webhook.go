@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Webhook POSTs a JSON document. Without a template the message data is sent
+// as is, otherwise the rendered template is the request body.
+type Webhook struct {
+	URL            string            `json:"url" yaml:"url"`
+	Headers        map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Template       string            `json:"template,omitempty" yaml:"template,omitempty"`
+	TimeoutSeconds int               `json:"timeoutSeconds,omitempty" yaml:"timeout_seconds,omitempty"`
+}
+
+func (w Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook url must be an absolute http(s) URL: %q", w.URL)
+	}
+	if w.Template != "" {
+		if _, err := parse("webhook", w.Template); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w Webhook) Send(ctx context.Context, msg Message) error {
+	var body []byte
+	if w.Template != "" {
+		text, err := render("webhook", w.Template, msg.Data)
+		if err != nil {
+			return err
+		}
+		body = []byte(text)
+	} else {
+		var err error
+		if body, err = json.Marshal(msg.Data); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeoutOr(w.TimeoutSeconds))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package tail
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var levelPattern = regexp.MustCompile(`(?i)\b(TRACE|DEBUG|INFO|WARN|WARNING|ERROR|FATAL|CRITICAL)\b`)
+
+var levelRank = map[string]int{
+	"TRACE":    0,
+	"DEBUG":    1,
+	"INFO":     2,
+	"WARN":     3,
+	"WARNING":  3,
+	"ERROR":    4,
+	"FATAL":    5,
+	"CRITICAL": 5,
+}
+
+// Filter keeps lines at or above MinLevel that match Pattern. Lines without a
+// level, such as stack trace continuations, follow the previous line's verdict.
+type Filter struct {
+	minRank   int
+	pattern   *regexp.Regexp
+	lastMatch bool
+}
+
+func NewFilter(minLevel, pattern string) (*Filter, error) {
+	f := &Filter{minRank: -1, lastMatch: true}
+
+	if minLevel != "" {
+		rank, ok := levelRank[strings.ToUpper(minLevel)]
+		if !ok {
+			return nil, fmt.Errorf("unknown log level: %s", minLevel)
+		}
+		f.minRank = rank
+	}
+
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter pattern: %w", err)
+		}
+		f.pattern = re
+	}
+
+	return f, nil
+}
+
+// Level returns the upper-cased level found in text, or "" when there is none.
+func Level(text string) string {
+	m := levelPattern.FindStringSubmatch(text)
+	if m == nil {
+		return ""
+	}
+	return strings.ToUpper(m[1])
+}
+
+func (f *Filter) Match(text string) bool {
+	if f.minRank >= 0 {
+		level := Level(text)
+		if level == "" {
+			return f.lastMatch
+		}
+		if levelRank[level] < f.minRank {
+			f.lastMatch = false
+			return false
+		}
+	}
+
+	f.lastMatch = f.pattern == nil || f.pattern.MatchString(text)
+	return f.lastMatch
+}
+
+// Apply returns the lines that pass the filter, in order.
+func (f *Filter) Apply(lines []Line) []Line {
+	kept := lines[:0:0]
+	for _, l := range lines {
+		if f.Match(l.Text) {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
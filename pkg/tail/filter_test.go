@@ -0,0 +1,90 @@
+package tail
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	lines := []string{
+		"2026-01-01 10:00:00 DEBUG cache warmed",
+		"2026-01-01 10:00:01 INFO request served",
+		"2026-01-01 10:00:02 WARN slow query on orders",
+		"2026-01-01 10:00:03 ERROR timeout calling payments",
+		"   at Payments.Client.Call()",
+		"   at Orders.Checkout()",
+		"2026-01-01 10:00:04 info request served",
+		"   continuation of an info line",
+		"2026-01-01 10:00:05 Warning: disk almost full",
+	}
+
+	tests := []struct {
+		name    string
+		level   string
+		pattern string
+		want    []int // indexes into lines
+	}{
+		{name: "no filter", want: []int{0, 1, 2, 3, 4, 5, 6, 7, 8}},
+		{name: "min level keeps continuations of kept lines", level: "warn", want: []int{2, 3, 4, 5, 8}},
+		{name: "min level error", level: "ERROR", want: []int{3, 4, 5}},
+		{name: "pattern", pattern: "orders|Orders", want: []int{2, 5}},
+		{name: "level and pattern", level: "warn", pattern: "payments", want: []int{3, 4, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFilter(tt.level, tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for i, l := range lines {
+				if f.Match(l) {
+					got = append(got, i)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterApply(t *testing.T) {
+	f, err := NewFilter("error", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := []Line{{Text: "INFO a"}, {Text: "ERROR b"}, {Text: "  at main()"}, {Text: "INFO c"}}
+	got := f.Apply(in)
+	if len(got) != 2 || got[0].Text != "ERROR b" || got[1].Text != "  at main()" {
+		t.Errorf("Apply() = %+v", got)
+	}
+	if in[0].Text != "INFO a" {
+		t.Error("Apply() modified its input")
+	}
+}
+
+func TestNewFilterErrors(t *testing.T) {
+	if _, err := NewFilter("loud", ""); err == nil {
+		t.Error("NewFilter() accepted an unknown level")
+	}
+	if _, err := NewFilter("", "(unclosed"); err == nil {
+		t.Error("NewFilter() accepted an invalid pattern")
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := map[string]string{
+		"[warning] low memory":    "WARNING",
+		"level=error msg=boom":    "ERROR",
+		"FATAL could not bind":    "FATAL",
+		"no level here":           "",
+		"INFORMATIONAL is a word": "",
+	}
+	for text, want := range tests {
+		if got := Level(text); got != want {
+			t.Errorf("Level(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package tail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPollInterval = 500 * time.Millisecond
+	maxBatchLines       = 500
+	maxLineBytes        = 64 * 1024
+	readChunk           = 32 * 1024
+)
+
+type Line struct {
+	File   string `json:"file"`
+	Offset int64  `json:"offset"` // byte offset of the line start in File
+	Text   string `json:"text"`
+	Time   int64  `json:"time"` // Unix ms when the line was read
+}
+
+type Options struct {
+	Backfill     int           // number of existing lines to emit before following
+	FromStart    bool          // read the current file from the beginning instead of the end
+	PollInterval time.Duration // how often the file and glob are re-checked
+}
+
+// Follow tails the newest file matching pattern until ctx is done. It switches
+// to a newer match when dated files roll over and reopens the file when it is
+// truncated or replaced. Lines are delivered in batches through emit.
+func Follow(ctx context.Context, pattern string, opts Options, emit func([]Line)) error {
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	f := &follower{pattern: filepath.Clean(os.ExpandEnv(pattern)), emit: emit}
+	defer f.close()
+
+	if err := f.open(opts.FromStart, opts.Backfill); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := f.poll(); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+}
+
+// Resolve returns the newest file matching pattern.
+func Resolve(pattern string) (string, error) {
+	pattern = filepath.Clean(os.ExpandEnv(pattern))
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid log pattern %q: %w", pattern, err)
+	}
+
+	var newest string
+	var newestMod time.Time
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if newest == "" || info.ModTime().After(newestMod) {
+			newest, newestMod = m, info.ModTime()
+		}
+	}
+	if newest == "" {
+		return "", fmt.Errorf("no log file matches %s: %w", pattern, os.ErrNotExist)
+	}
+	return newest, nil
+}
+
+type follower struct {
+	pattern string
+	emit    func([]Line)
+
+	path    string
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte
+
+	// Where reading stopped in files followed earlier, so switching back to
+	// one when several matches are written in turn does not repeat it
+	seen map[string]position
+}
+
+type position struct {
+	info   os.FileInfo
+	offset int64
+}
+
+func (f *follower) open(fromStart bool, backfill int) error {
+	path, err := Resolve(f.pattern)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.close()
+	f.path, f.file, f.info = path, file, info
+	f.partial = nil
+	f.offset = 0
+
+	if fromStart {
+		return f.read()
+	}
+
+	f.offset = info.Size()
+	if backfill > 0 {
+		lines, err := lastLines(file, path, info.Size(), backfill)
+		if err != nil {
+			return err
+		}
+		f.deliver(lines)
+	}
+	return nil
+}
+
+func (f *follower) poll() error {
+	if f.file == nil {
+		// Nothing existed yet, anything that appears is new
+		return f.open(true, 0)
+	}
+
+	if newest, err := Resolve(f.pattern); err == nil && newest != f.path {
+		// Drain what is left of the old file before switching to the new one
+		if err := f.read(); err != nil {
+			return err
+		}
+		return f.switchTo(newest)
+	}
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// Rotated away and not yet recreated, the open handle still
+			// reads what was written before the rename
+			return f.read()
+		}
+		return err
+	}
+
+	if !os.SameFile(info, f.info) {
+		// Replaced, finish the old file first
+		if err := f.read(); err != nil {
+			return err
+		}
+		return f.open(true, 0)
+	}
+	if info.Size() < f.readPos() {
+		// Truncated in place
+		return f.open(true, 0)
+	}
+	f.info = info
+
+	if info.Size() > f.readPos() {
+		return f.read()
+	}
+	return nil
+}
+
+// switchTo follows path from where it was left, or from the start when it was
+// not followed before or has been replaced or truncated since.
+func (f *follower) switchTo(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	if f.seen == nil {
+		f.seen = make(map[string]position)
+	}
+	// Lines cut off by the switch are read again from their start next time
+	f.seen[f.path] = position{info: f.info, offset: f.offset}
+	for p := range f.seen {
+		if _, err := os.Stat(p); err != nil {
+			delete(f.seen, p)
+		}
+	}
+
+	var offset int64
+	if pos, ok := f.seen[path]; ok && os.SameFile(pos.info, info) && info.Size() >= pos.offset {
+		offset = pos.offset
+	}
+	delete(f.seen, path)
+
+	f.close()
+	f.path, f.file, f.info = path, file, info
+	f.partial = nil
+	f.offset = offset
+	return f.read()
+}
+
+// readPos is where the next read starts, past any unterminated line already buffered.
+func (f *follower) readPos() int64 {
+	return f.offset + int64(len(f.partial))
+}
+
+func (f *follower) read() error {
+	if _, err := f.file.Seek(f.readPos(), io.SeekStart); err != nil {
+		return err
+	}
+
+	buf := make([]byte, readChunk)
+	var batch []Line
+	for {
+		n, err := f.file.Read(buf)
+		if n > 0 {
+			batch = f.split(buf[:n], batch)
+			if len(batch) >= maxBatchLines {
+				f.deliver(batch)
+				batch = nil
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	f.deliver(batch)
+	return nil
+}
+
+// split cuts data into complete lines, keeping an unterminated tail for the next read.
+func (f *follower) split(data []byte, batch []Line) []Line {
+	now := time.Now().UnixMilli()
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			f.partial = append(f.partial, data...)
+			if len(f.partial) > maxLineBytes {
+				batch = append(batch, f.line(f.partial, now))
+				f.offset += int64(len(f.partial))
+				f.partial = nil
+			}
+			return batch
+		}
+
+		chunk := data[:i]
+		if len(f.partial) > 0 {
+			chunk = append(f.partial, chunk...)
+			f.partial = nil
+		}
+		batch = append(batch, f.line(chunk, now))
+		f.offset += int64(len(chunk)) + 1
+		data = data[i+1:]
+	}
+	return batch
+}
+
+func (f *follower) line(raw []byte, now int64) Line {
+	return Line{
+		File:   f.path,
+		Offset: f.offset,
+		Text:   strings.TrimRight(string(raw), "\r"),
+		Time:   now,
+	}
+}
+
+func (f *follower) deliver(lines []Line) {
+	if len(lines) > 0 {
+		f.emit(lines)
+	}
+}
+
+func (f *follower) close() {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+}
+
+// lastLines reads backwards from size until n complete lines are found.
+func lastLines(file *os.File, path string, size int64, n int) ([]Line, error) {
+	var data []byte
+	pos := size
+	for pos > 0 && bytes.Count(data, []byte{'\n'}) <= n {
+		step := min(int64(readChunk), pos)
+		pos -= step
+		chunk := make([]byte, step)
+		if _, err := file.ReadAt(chunk, pos); err != nil && err != io.EOF {
+			return nil, err
+		}
+		data = append(chunk, data...)
+	}
+
+	// Drop the trailing newline so it does not produce an empty last line
+	end := len(data)
+	if end > 0 && data[end-1] == '\n' {
+		end--
+	}
+
+	now := time.Now().UnixMilli()
+	var lines []Line
+	for end > 0 && len(lines) < n {
+		start := bytes.LastIndexByte(data[:end], '\n') + 1
+		if start == 0 && pos > 0 {
+			// The first line is cut off by the read window
+			break
+		}
+		lines = append(lines, Line{
+			File:   path,
+			Offset: pos + int64(start),
+			Text:   strings.TrimRight(string(data[start:end]), "\r"),
+			Time:   now,
+		})
+		end = start - 1
+	}
+
+	slices.Reverse(lines)
+	return lines, nil
+}
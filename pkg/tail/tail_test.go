@@ -0,0 +1,288 @@
+package tail
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// recorder collects what a follower emits.
+type recorder struct {
+	lines []Line
+}
+
+func (r *recorder) emit(lines []Line) {
+	r.lines = append(r.lines, lines...)
+}
+
+// take returns the texts emitted since the last call.
+func (r *recorder) take() []string {
+	var out []string
+	for _, l := range r.lines {
+		out = append(out, l.Text)
+	}
+	r.lines = nil
+	return out
+}
+
+func newFollower(pattern string) (*follower, *recorder) {
+	r := &recorder{}
+	return &follower{pattern: filepath.Clean(pattern), emit: r.emit}, r
+}
+
+func write(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func appendTo(t *testing.T, path, data string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// touch sets the modification time Resolve uses to pick the newest match.
+func touch(t *testing.T, path string, at time.Time) {
+	t.Helper()
+	if err := os.Chtimes(path, at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func poll(t *testing.T, f *follower) {
+	t.Helper()
+	if err := f.poll(); err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+}
+
+func expect(t *testing.T, r *recorder, want ...string) {
+	t.Helper()
+	if got := r.take(); !slices.Equal(got, want) {
+		t.Fatalf("emitted %q, want %q", got, want)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	write(t, path, "old 1\nold 2\n")
+
+	f, r := newFollower(path)
+	defer f.close()
+	if err := f.open(false, 0); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, r)
+
+	appendTo(t, path, "new 1\nnew 2\r\n")
+	poll(t, f)
+	expect(t, r, "new 1", "new 2")
+
+	poll(t, f)
+	expect(t, r)
+}
+
+func TestBackfill(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	write(t, path, "1\n2\n3\n4\n")
+
+	f, r := newFollower(path)
+	defer f.close()
+	if err := f.open(false, 2); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, r, "3", "4")
+
+	g, r := newFollower(path)
+	defer g.close()
+	if err := g.open(true, 0); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, r, "1", "2", "3", "4")
+}
+
+func TestPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	write(t, path, "")
+
+	f, r := newFollower(path)
+	defer f.close()
+	if err := f.open(false, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	appendTo(t, path, "first\nsec")
+	poll(t, f)
+	expect(t, r, "first")
+
+	// The unterminated tail is held back until its newline arrives
+	appendTo(t, path, "ond")
+	poll(t, f)
+	expect(t, r)
+
+	appendTo(t, path, "\nthird\n")
+	poll(t, f)
+	lines := r.lines
+	expect(t, r, "second", "third")
+	if lines[0].Offset != 6 || lines[1].Offset != 13 {
+		t.Errorf("offsets = %d, %d, want 6, 13", lines[0].Offset, lines[1].Offset)
+	}
+}
+
+func TestRenameRotate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	write(t, path, "")
+
+	f, r := newFollower(path)
+	defer f.close()
+	if err := f.open(false, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	appendTo(t, path, "before\n")
+	poll(t, f)
+	expect(t, r, "before")
+
+	// Written just before the rotation, not seen by a poll yet
+	appendTo(t, path, "last of old\n")
+	if err := os.Rename(path, filepath.Join(dir, "app.log.1")); err != nil {
+		t.Fatal(err)
+	}
+	poll(t, f)
+	expect(t, r, "last of old")
+
+	write(t, path, "first of new\n")
+	poll(t, f)
+	expect(t, r, "first of new")
+
+	appendTo(t, path, "second of new\n")
+	poll(t, f)
+	expect(t, r, "second of new")
+}
+
+func TestCopyTruncate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	write(t, path, "")
+
+	f, r := newFollower(path)
+	defer f.close()
+	if err := f.open(false, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	appendTo(t, path, "one\ntwo\n")
+	poll(t, f)
+	expect(t, r, "one", "two")
+
+	// logrotate copytruncate keeps the inode and cuts the file to zero
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	appendTo(t, path, "three\n")
+	poll(t, f)
+	expect(t, r, "three")
+
+	appendTo(t, path, "four\n")
+	poll(t, f)
+	expect(t, r, "four")
+}
+
+func TestDatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "app-*.log")
+	day1 := filepath.Join(dir, "app-20260101.log")
+	day2 := filepath.Join(dir, "app-20260102.log")
+	base := time.Now().Add(-time.Hour)
+
+	write(t, day1, "a1\n")
+	touch(t, day1, base)
+
+	f, r := newFollower(pattern)
+	defer f.close()
+	if err := f.open(false, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rolling over drains the old file, then reads the new one from its start
+	appendTo(t, day1, "a2\n")
+	touch(t, day1, base.Add(time.Minute))
+	write(t, day2, "b1\n")
+	touch(t, day2, base.Add(2*time.Minute))
+	poll(t, f)
+	expect(t, r, "a2", "b1")
+
+	// Switching back resumes where the old file was left
+	appendTo(t, day1, "a3\n")
+	touch(t, day1, base.Add(3*time.Minute))
+	poll(t, f)
+	expect(t, r, "a3")
+
+	appendTo(t, day2, "b2\n")
+	touch(t, day2, base.Add(4*time.Minute))
+	poll(t, f)
+	expect(t, r, "b2")
+}
+
+func TestFileAppearsLater(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	f, r := newFollower(path)
+	defer f.close()
+	if err := f.open(false, 0); err == nil {
+		t.Fatal("open() of a missing file succeeded")
+	}
+	if err := f.poll(); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("poll() error = %v, want ErrNotExist", err)
+	}
+
+	write(t, path, "hello\n")
+	poll(t, f)
+	expect(t, r, "hello")
+}
+
+func TestFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	write(t, path, "old\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lines := make(chan Line, 10)
+	done := make(chan error)
+	go func() {
+		done <- Follow(ctx, path, Options{PollInterval: 10 * time.Millisecond}, func(batch []Line) {
+			for _, l := range batch {
+				lines <- l
+			}
+		})
+	}()
+
+	// Give Follow time to open the file at its end
+	time.Sleep(50 * time.Millisecond)
+	appendTo(t, path, "new\n")
+
+	select {
+	case l := <-lines:
+		if l.Text != "new" || l.File != path {
+			t.Errorf("got %+v, want the new line of %s", l, path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no line delivered")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Follow() error = %v", err)
+	}
+}